@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/chtozamm/chirpy/internal/auth"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// authenticate returns the ID of the user whose access token is in the
+// Authorization header of r. Errors are safe to show to the client.
+func (cfg *apiConfig) authenticate(r *http.Request) (pgtype.UUID, error) {
+	accessToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return pgtype.UUID{}, errors.New("Access token is missing or invalid in the Authorization header")
+	}
+
+	id, err := auth.ValidateJWT(accessToken, cfg.authSecret)
+	if err != nil {
+		return pgtype.UUID{}, errors.New("Invalid access token")
+	}
+
+	return pgtype.UUID{Bytes: id, Valid: true}, nil
+}
+
+// viewer returns the ID of the user making the request, or an invalid UUID
+// for anonymous requests. A token that is present but invalid is an error.
+func (cfg *apiConfig) viewer(r *http.Request) (pgtype.UUID, error) {
+	if r.Header.Get("Authorization") == "" {
+		return pgtype.UUID{}, nil
+	}
+	return cfg.authenticate(r)
+}
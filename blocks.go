@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// hiddenUsers returns which of userIDs viewer must not see: users who blocked
+// viewer or whom viewer blocked or muted. Nothing is hidden from anonymous
+// viewers.
+func (cfg *apiConfig) hiddenUsers(ctx context.Context, viewer pgtype.UUID, userIDs []pgtype.UUID) (map[pgtype.UUID]bool, error) {
+	hidden := make(map[pgtype.UUID]bool)
+	if !viewer.Valid || len(userIDs) == 0 {
+		return hidden, nil
+	}
+
+	ids, err := cfg.db.GetHiddenUserIDs(ctx, database.GetHiddenUserIDsParams{ViewerID: viewer, UserIds: userIDs})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		hidden[id] = true
+	}
+	return hidden, nil
+}
+
+// allHiddenUsers returns every user viewer must not see, for filtering
+// chirps whose authors are not known in advance.
+func (cfg *apiConfig) allHiddenUsers(ctx context.Context, viewer pgtype.UUID) (map[pgtype.UUID]bool, error) {
+	hidden := make(map[pgtype.UUID]bool)
+	if !viewer.Valid {
+		return hidden, nil
+	}
+
+	ids, err := cfg.db.GetAllHiddenUserIDs(ctx, viewer)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		hidden[id] = true
+	}
+	return hidden, nil
+}
+
+// checkNotBlocked returns pgx.ErrNoRows if userID and the author of chirp
+// have blocked one another: a blocked user cannot see the chirp, so liking,
+// rechirping or replying to it fails as if it did not exist.
+func (cfg *apiConfig) checkNotBlocked(ctx context.Context, userID pgtype.UUID, chirp database.Chirp) error {
+	blocked, err := cfg.db.IsBlocked(ctx, database.IsBlockedParams{UserID: userID, OtherID: chirp.UserID})
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handleBlockUser(w http.ResponseWriter, r *http.Request) {
+	blocker, blocked, ok := cfg.userTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if blocker == blocked.ID {
+		http.Error(w, "you cannot block yourself", http.StatusBadRequest)
+		return
+	}
+
+	_, err := cfg.db.CreateBlock(context.Background(), database.CreateBlockParams{
+		BlockerID: blocker,
+		BlockedID: blocked.ID,
+	})
+	if err != nil {
+		log.Printf("Error creating block: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnblockUser(w http.ResponseWriter, r *http.Request) {
+	blocker, blocked, ok := cfg.userTarget(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.DeleteBlock(context.Background(), database.DeleteBlockParams{
+		BlockerID: blocker,
+		BlockedID: blocked.ID,
+	})
+	if err != nil {
+		log.Printf("Error deleting block: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleMuteUser(w http.ResponseWriter, r *http.Request) {
+	muter, muted, ok := cfg.userTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if muter == muted.ID {
+		http.Error(w, "you cannot mute yourself", http.StatusBadRequest)
+		return
+	}
+
+	_, err := cfg.db.CreateMute(context.Background(), database.CreateMuteParams{
+		MuterID: muter,
+		MutedID: muted.ID,
+	})
+	if err != nil {
+		log.Printf("Error creating mute: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnmuteUser(w http.ResponseWriter, r *http.Request) {
+	muter, muted, ok := cfg.userTarget(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.DeleteMute(context.Background(), database.DeleteMuteParams{
+		MuterID: muter,
+		MutedID: muted.ID,
+	})
+	if err != nil {
+		log.Printf("Error deleting mute: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetBlocks(w http.ResponseWriter, r *http.Request) {
+	cfg.listOwnRelations(w, r, "blocks", func(ctx context.Context, params database.GetBlocksParams) ([]userEdge, error) {
+		rows, err := cfg.db.GetBlocks(ctx, params)
+		var edges []userEdge
+		for _, row := range rows {
+			edges = append(edges, userEdge{User: row.User, CreatedAt: row.BlockedAt})
+		}
+		return edges, err
+	})
+}
+
+func (cfg *apiConfig) handleGetMutes(w http.ResponseWriter, r *http.Request) {
+	cfg.listOwnRelations(w, r, "mutes", func(ctx context.Context, params database.GetBlocksParams) ([]userEdge, error) {
+		rows, err := cfg.db.GetMutes(ctx, database.GetMutesParams(params))
+		var edges []userEdge
+		for _, row := range rows {
+			edges = append(edges, userEdge{User: row.User, CreatedAt: row.MutedAt})
+		}
+		return edges, err
+	})
+}
+
+// listOwnRelations writes a page of the public profiles returned by query for
+// the caller, most recent first. Blocks and mutes are private, so there is no
+// way to list someone else's.
+func (cfg *apiConfig) listOwnRelations(w http.ResponseWriter, r *http.Request, name string, query func(context.Context, database.GetBlocksParams) ([]userEdge, error)) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, name+" are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	edges, err := query(context.Background(), database.GetBlocksParams{
+		UserID:          userID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting %s from db: %v\n", name, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	edges, next := trimPage(edges, page, userEdgeCursor)
+
+	profiles := []publicProfile{}
+	for _, edge := range edges {
+		profiles = append(profiles, newPublicProfile(edge.User))
+	}
+
+	resp, err := json.Marshal(profiles)
+	if err != nil {
+		log.Printf("Error marshalling profiles: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (ts *testServer) block(blocker, blocked testUser) {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/users/"+blocked.ID+"/block", blocker.bearer(), nil)
+	require.Equal(ts.t, http.StatusNoContent, rec.Code, rec.Body.String())
+}
+
+// chirpIDs returns the IDs of the chirps listed at path as seen by user.
+func (ts *testServer) chirpIDs(t *testing.T, user testUser, path string) []string {
+	t.Helper()
+
+	rec := ts.do(http.MethodGet, path, user.bearer(), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var ids []string
+	for _, chirp := range decode[[]testChirp](t, rec) {
+		ids = append(ids, chirp.ID)
+	}
+	return ids
+}
+
+func TestBlock(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	carol := ts.signup("carol@example.com")
+
+	chirp := ts.createChirp(alice, "hello from alice")
+	ts.follow(bob, alice)
+	ts.follow(alice, bob)
+
+	t.Run("Self", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users/"+alice.ID+"/block", alice.bearer(), nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Removes Follows", func(t *testing.T) {
+		ts.block(alice, bob)
+
+		rec := ts.do(http.MethodGet, "/api/users/"+alice.ID, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		profile := decode[map[string]any](t, rec)
+		assert.EqualValues(t, 0, profile["followers_count"])
+		assert.EqualValues(t, 0, profile["following_count"])
+	})
+
+	t.Run("Blocked Cannot Interact", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users/"+alice.ID+"/follow", bob.bearer(), nil)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = ts.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/like", bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = ts.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/rechirp", bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = ts.do(http.MethodPost, "/api/chirps", bob.bearer(), map[string]string{"body": "hi", "in_reply_to": chirp.ID})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Hidden Both Ways", func(t *testing.T) {
+		bobChirp := ts.createChirp(bob, "hello from bob")
+
+		rec := ts.do(http.MethodGet, "/api/chirps/"+chirp.ID, bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = ts.do(http.MethodGet, "/api/chirps/"+bobChirp.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = ts.do(http.MethodGet, "/api/chirps/"+chirp.ID+"/likes", bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		assert.NotContains(t, ts.chirpIDs(t, bob, "/api/chirps"), chirp.ID)
+		assert.NotContains(t, ts.chirpIDs(t, bob, "/api/chirps?author_id="+alice.ID), chirp.ID)
+		assert.NotContains(t, ts.chirpIDs(t, bob, "/api/chirps/search?q=hello"), chirp.ID)
+		assert.NotContains(t, ts.chirpIDs(t, alice, "/api/chirps/search?q=hello"), bobChirp.ID)
+		assert.Contains(t, ts.chirpIDs(t, carol, "/api/chirps"), chirp.ID)
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+chirp.ID, "", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/blocks", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		blocks := decode[[]map[string]any](t, rec)
+		require.Len(t, blocks, 1)
+		assert.Equal(t, bob.ID, blocks[0]["id"])
+
+		rec = ts.do(http.MethodGet, "/api/blocks", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, decode[[]map[string]any](t, rec))
+	})
+
+	t.Run("Unblock", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, "/api/users/"+bob.ID+"/block", alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+chirp.ID, bob.bearer(), nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		ts.follow(bob, alice)
+	})
+}
+
+func TestMute(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	carol := ts.signup("carol@example.com")
+
+	chirp := ts.createChirp(bob, "hello from bob")
+	ts.follow(alice, bob)
+
+	rec := ts.do(http.MethodPost, "/api/users/"+bob.ID+"/mute", alice.bearer(), nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = ts.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/rechirp", carol.bearer(), nil)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	rechirp := decode[testChirp](t, rec)
+
+	t.Run("Hidden From Muter", func(t *testing.T) {
+		assert.NotContains(t, ts.chirpIDs(t, alice, "/api/timeline"), chirp.ID)
+		assert.NotContains(t, ts.chirpIDs(t, alice, "/api/chirps"), chirp.ID)
+
+		rec := ts.do(http.MethodGet, "/api/chirps/"+chirp.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Rechirp By Others", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps/"+rechirp.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		shared := decode[map[string]any](t, rec)
+		require.Contains(t, shared, "original")
+		original := shared["original"].(map[string]any)
+		assert.Equal(t, chirp.ID, original["id"])
+		assert.Equal(t, true, original["unavailable"])
+		assert.Empty(t, original["body"])
+	})
+
+	t.Run("Still Visible To Others", func(t *testing.T) {
+		assert.Contains(t, ts.chirpIDs(t, carol, "/api/chirps"), chirp.ID)
+
+		rec := ts.do(http.MethodGet, "/api/chirps/"+chirp.ID, bob.bearer(), nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Hidden From User Lists", func(t *testing.T) {
+		carolChirp := ts.createChirp(carol, "hello from carol")
+		ts.follow(bob, carol)
+		ts.follow(carol, bob)
+		ts.like(bob, carolChirp)
+
+		profileIDs := func(t *testing.T, user testUser, path string) []string {
+			rec := ts.do(http.MethodGet, path, user.bearer(), nil)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			var ids []string
+			for _, profile := range decode[[]map[string]any](t, rec) {
+				ids = append(ids, profile["id"].(string))
+			}
+			return ids
+		}
+
+		for _, path := range []string{
+			"/api/users/" + carol.ID + "/followers",
+			"/api/users/" + carol.ID + "/following",
+			"/api/chirps/" + carolChirp.ID + "/likes",
+		} {
+			assert.NotContains(t, profileIDs(t, alice, path), bob.ID, path)
+			assert.Contains(t, profileIDs(t, carol, path), bob.ID, path)
+		}
+	})
+
+	t.Run("List And Unmute", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/mutes", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		mutes := decode[[]map[string]any](t, rec)
+		require.Len(t, mutes, 1)
+		assert.Equal(t, bob.ID, mutes[0]["id"])
+
+		rec = ts.do(http.MethodDelete, "/api/users/"+bob.ID+"/mute", alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Contains(t, ts.chirpIDs(t, alice, "/api/timeline"), chirp.ID)
+	})
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (cfg *apiConfig) handleBookmarkChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	chirpID := pgtype.UUID{}
+	err = chirpID.Scan(r.PathValue("chirpID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(context.Background(), chirpID)
+	if err == nil {
+		err = cfg.checkNotBlocked(context.Background(), userID, chirp)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting chirp from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = cfg.db.CreateBookmark(context.Background(), database.CreateBookmarkParams{UserID: userID, ChirpID: chirpID})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error creating bookmark: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleDeleteBookmark(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	chirpID := pgtype.UUID{}
+	err = chirpID.Scan(r.PathValue("chirpID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	_, err = cfg.db.DeleteBookmark(context.Background(), database.DeleteBookmarkParams{UserID: userID, ChirpID: chirpID})
+	if err != nil {
+		log.Printf("Error deleting bookmark: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetBookmarks lists the caller's bookmarks, most recently saved first.
+// Bookmarks are private, so there is no way to list someone else's.
+func (cfg *apiConfig) handleGetBookmarks(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "bookmarks are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := cfg.db.GetBookmarks(context.Background(), database.GetBookmarksParams{
+		UserID:          userID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting bookmarks from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rows, next := trimPage(rows, page, func(row database.GetBookmarksRow) pageCursor {
+		return newPageCursor(row.BookmarkedAt, row.Chirp.ID)
+	})
+
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = row.Chirp
+	}
+
+	responses, err := cfg.chirpResponses(context.Background(), userID, chirps)
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses)
+	if err != nil {
+		log.Printf("Error marshalling chirps struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
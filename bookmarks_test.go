@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBookmarkedChirp struct {
+	ID             string `json:"id"`
+	BookmarkedByMe bool   `json:"bookmarked_by_me"`
+}
+
+func TestBookmarks(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	first := ts.createChirp(alice, "first")
+	second := ts.createChirp(alice, "second")
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps/"+first.ID+"/bookmark", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/bookmarks", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Bookmark Order", func(t *testing.T) {
+		// Saved in the opposite order to which the chirps were created.
+		for _, chirp := range []testChirp{second, first} {
+			rec := ts.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/bookmark", bob.bearer(), nil)
+			require.Equal(t, http.StatusNoContent, rec.Code)
+		}
+
+		rec := ts.do(http.MethodGet, "/api/bookmarks?limit=1", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []testBookmarkedChirp{{ID: first.ID, BookmarkedByMe: true}}, decode[[]testBookmarkedChirp](t, rec))
+
+		rec = ts.do(http.MethodGet, "/api/bookmarks?limit=1&cursor="+rec.Header().Get("X-Next-Cursor"), bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []testBookmarkedChirp{{ID: second.ID, BookmarkedByMe: true}}, decode[[]testBookmarkedChirp](t, rec))
+	})
+
+	t.Run("Private", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/bookmarks", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, decode[[]testBookmarkedChirp](t, rec))
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+first.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.False(t, decode[testBookmarkedChirp](t, rec).BookmarkedByMe)
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, "/api/chirps/"+first.ID+"/bookmark", bob.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/bookmarks", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []testBookmarkedChirp{{ID: second.ID, BookmarkedByMe: true}}, decode[[]testBookmarkedChirp](t, rec))
+	})
+
+	t.Run("Chirp Deleted", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, "/api/chirps/"+second.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/bookmarks", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, decode[[]testBookmarkedChirp](t, rec))
+	})
+}
@@ -5,30 +5,31 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
-	"slices"
+	"time"
 
-	"github.com/chtozamm/chirpy/internal/auth"
 	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func (cfg *apiConfig) handleCreateChirp(w http.ResponseWriter, r *http.Request) {
-	token, err := auth.GetBearerToken(r.Header)
+	userID, err := cfg.authenticate(r)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-		return
-	}
-
-	userID, err := auth.ValidateJWT(token, cfg.authSecret)
-	if err != nil {
-		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
 	type parameters struct {
-		Body string `json:"body"`
+		Body      string            `json:"body"`
+		QuoteOf   string            `json:"quote_of"`
+		InReplyTo string            `json:"in_reply_to"`
+		Media     []mediaAttachment `json:"media"`
+		Poll      *pollParameters   `json:"poll"`
+		PublishAt *time.Time        `json:"publish_at"`
+		ExpiresIn *int64            `json:"expires_in"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -40,32 +41,120 @@ func (cfg *apiConfig) handleCreateChirp(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if params.Body == "" {
+	if params.Body == "" && len(params.Media) == 0 {
 		http.Error(w, "body cannot be empty", http.StatusBadRequest)
 		return
 	}
 
-	pgUUID := pgtype.UUID{}
-	err = pgUUID.Scan(userID.String())
+	if params.PublishAt != nil {
+		if params.QuoteOf != "" || params.InReplyTo != "" || len(params.Media) > 0 || params.Poll != nil || params.ExpiresIn != nil {
+			http.Error(w, "scheduled chirps can only have a body", http.StatusBadRequest)
+			return
+		}
+		cfg.createScheduledChirp(w, userID, params.Body, *params.PublishAt)
+		return
+	}
+
+	var expiresAt pgtype.Timestamp
+	if params.ExpiresIn != nil {
+		expiresAt, err = parseExpiresIn(*params.ExpiresIn)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var pollParams *database.CreatePollParams
+	if params.Poll != nil {
+		poll, err := parsePoll(*params.Poll)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		pollParams = &poll
+	}
+
+	attachParams, err := parseMediaAttachments(userID, params.Media)
 	if err != nil {
-		log.Printf("Error scanning user_id from request into pgtype.UUID: %v\n", err)
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if !pgUUID.Valid {
-		log.Printf("Failed to validate pgUUID: %v is invalid\n", pgUUID.String())
+	if len(attachParams.Ids) > 0 {
+		err = cfg.checkMediaAttachable(context.Background(), attachParams)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				http.Error(w, errInvalidMedia.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Printf("Error getting media from db: %v\n", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	chirpParams, err := cfg.prepareChirp(context.Background(), userID, params.Body, params.QuoteOf, params.InReplyTo)
+	if err != nil {
+		var inputErr chirpInputError
+		if errors.As(err, &inputErr) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error preparing chirp: %v\n", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	chirpParams.ExpiresAt = expiresAt
 
-	newChirp, err := cfg.db.CreateChirp(context.Background(), database.CreateChirpParams{Body: params.Body, UserID: pgUUID})
+	// The chirp, its media and its poll are created together, so the chirp
+	// is never seen without what the user posted with it.
+	var newChirp database.Chirp
+	err = cfg.inTx(context.Background(), func(db database.Querier) error {
+		newChirp, err = db.CreateChirp(context.Background(), chirpParams)
+		if err != nil {
+			return fmt.Errorf("creating chirp: %w", err)
+		}
+
+		if len(attachParams.Ids) > 0 {
+			attachParams.ChirpID = newChirp.ID
+			attached, err := db.AttachMedia(context.Background(), attachParams)
+			if err != nil {
+				return fmt.Errorf("attaching media: %w", err)
+			}
+			if attached != int64(len(attachParams.Ids)) {
+				// The media was attached elsewhere since it was checked.
+				return errInvalidMedia
+			}
+		}
+
+		if pollParams != nil {
+			pollParams.ChirpID = newChirp.ID
+			_, err = db.CreatePoll(context.Background(), *pollParams)
+			if err != nil {
+				return fmt.Errorf("creating poll: %w", err)
+			}
+		}
+		return nil
+	})
 	if err != nil {
+		if errors.Is(err, errInvalidMedia) {
+			http.Error(w, errInvalidMedia.Error(), http.StatusBadRequest)
+			return
+		}
 		log.Printf("Error creating a chirp: %v\n", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	resp, err := json.Marshal(newChirp)
+	cfg.afterChirpCreated(context.Background(), newChirp)
+
+	responses, err := cfg.chirpResponses(context.Background(), userID, []database.Chirp{newChirp})
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses[0])
 	if err != nil {
 		log.Printf("Error marshalling chirp struct: %v\n", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -76,53 +165,185 @@ func (cfg *apiConfig) handleCreateChirp(w http.ResponseWriter, r *http.Request)
 	w.Write(resp)
 }
 
+// chirpInputError is a problem with the content of a new chirp that the
+// client has to fix.
+type chirpInputError string
+
+func (e chirpInputError) Error() string {
+	return string(e)
+}
+
+// prepareChirp validates the quoted chirp and reply parent of a new chirp by
+// userID and returns the parameters to create it. Both must exist and not be
+// by a user who blocked or was blocked by userID. Such problems are returned
+// as a chirpInputError; other errors come from the database.
+func (cfg *apiConfig) prepareChirp(ctx context.Context, userID pgtype.UUID, body, quoteOf, inReplyTo string) (database.CreateChirpParams, error) {
+	chirpParams := database.CreateChirpParams{
+		Body:   body,
+		UserID: userID,
+	}
+
+	if quoteOf != "" {
+		quoted, err := cfg.quotableChirp(quoteOf)
+		if err == nil {
+			err = cfg.checkNotBlocked(ctx, userID, quoted)
+		}
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return database.CreateChirpParams{}, chirpInputError("quote_of must be the ID of an existing chirp")
+			}
+			return database.CreateChirpParams{}, fmt.Errorf("getting quoted chirp: %w", err)
+		}
+		chirpParams.QuoteOf = quoted.ID
+	}
+
+	if inReplyTo != "" {
+		parent, err := cfg.quotableChirp(inReplyTo)
+		if err == nil {
+			err = cfg.checkNotBlocked(ctx, userID, parent)
+		}
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return database.CreateChirpParams{}, chirpInputError("in_reply_to must be the ID of an existing chirp")
+			}
+			return database.CreateChirpParams{}, fmt.Errorf("getting parent chirp: %w", err)
+		}
+		setReplyParent(&chirpParams, parent)
+	}
+
+	return chirpParams, nil
+}
+
+// afterChirpCreated does what follows the creation of a chirp, whether it
+// was posted, scheduled or published from a draft. These are side effects of
+// the chirp, so a failure is logged rather than losing it.
+func (cfg *apiConfig) afterChirpCreated(ctx context.Context, chirp database.Chirp) {
+	// Hashtags are an index over the body: `chirpy backfill-hashtags`
+	// restores any missing links.
+	err := indexHashtags(ctx, cfg.db, chirp)
+	if err != nil {
+		log.Printf("Error indexing hashtags of chirp %v: %v\n", chirp.ID, err)
+	}
+	err = cfg.notifyChirp(ctx, chirp)
+	if err != nil {
+		log.Printf("Error notifying users of chirp %v: %v\n", chirp.ID, err)
+	}
+	cfg.publishEvent(ctx, eventChirpCreated, chirpEvent{ID: chirp.ID, UserID: chirp.UserID})
+}
+
 func (cfg *apiConfig) handleGetChirps(w http.ResponseWriter, r *http.Request) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
 	authorID := r.URL.Query().Get("author_id")
-	sortOrder := r.URL.Query().Get("sort")
+
+	page, err := parsePageRequest(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	var chirps []database.Chirp
-	var err error
+	var pinned database.Chirp
 
 	if authorID != "" {
 		authorUUID := pgtype.UUID{}
-		authorUUID.Scan(authorID)
-
-		chirps, err = cfg.db.GetChirpsFromAuthor(context.Background(), authorUUID)
+		err = authorUUID.Scan(authorID)
 		if err != nil {
-			log.Printf("Error getting chirps from db: %v\n", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			http.Error(w, "invalid author_id", http.StatusBadRequest)
 			return
 		}
+
+		// The pinned chirp leads the first page, taking the place of one of
+		// the limit chirps, and is left out of the others, whichever the
+		// order.
+		if r.URL.Query().Get("cursor") == "" {
+			pinned, err = cfg.db.GetPinnedChirp(context.Background(), database.GetPinnedChirpParams{UserID: authorUUID, ViewerID: viewer})
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("Error getting pinned chirp from db: %v\n", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if pinned.ID.Valid {
+				page.Limit--
+			}
+		}
+
+		params := database.GetChirpsFromAuthorParams{
+			UserID:          authorUUID,
+			CursorCreatedAt: page.Cursor.Timestamp(),
+			CursorID:        page.Cursor.UUID(),
+			ViewerID:        viewer,
+			PageSize:        page.QueryLimit(),
+		}
+		if page.Desc {
+			chirps, err = cfg.db.GetChirpsFromAuthorDesc(context.Background(), database.GetChirpsFromAuthorDescParams(params))
+		} else {
+			chirps, err = cfg.db.GetChirpsFromAuthor(context.Background(), params)
+		}
 	} else {
-		chirps, err = cfg.db.GetChirps(context.Background())
-		if err != nil {
-			log.Printf("Error getting chirps from db: %v\n", err)
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-			return
+		params := database.GetChirpsParams{
+			CursorCreatedAt: page.Cursor.Timestamp(),
+			CursorID:        page.Cursor.UUID(),
+			ViewerID:        viewer,
+			PageSize:        page.QueryLimit(),
+		}
+		if page.Desc {
+			chirps, err = cfg.db.GetChirpsDesc(context.Background(), database.GetChirpsDescParams(params))
+		} else {
+			chirps, err = cfg.db.GetChirps(context.Background(), params)
 		}
 	}
+	if err != nil {
+		log.Printf("Error getting chirps from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
 
-	if sortOrder == "desc" {
-		slices.SortFunc(chirps, func(a, b database.Chirp) int {
-			return b.CreatedAt.Time.Compare(a.CreatedAt.Time)
-		})
+	chirps, next := trimPage(chirps, page, chirpCursor)
+	if pinned.ID.Valid {
+		chirps = append([]database.Chirp{pinned}, chirps...)
 	}
 
-	resp, err := json.Marshal(chirps)
+	responses, err := cfg.chirpResponses(context.Background(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if pinned.ID.Valid {
+		responses[0].Pinned = true
+	}
+
+	resp, err := json.Marshal(responses)
 	if err != nil {
 		log.Printf("Error marshalling chirps struct: %v\n", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
+	setNextPageHeaders(w, r, next)
 	w.Write(resp)
 }
 
+func chirpCursor(chirp database.Chirp) pageCursor {
+	return newPageCursor(chirp.CreatedAt, chirp.ID)
+}
+
 func (cfg *apiConfig) handleGetChirp(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
 	pgUUID := pgtype.UUID{}
-	err := pgUUID.Scan(r.PathValue("chirpID"))
+	err = pgUUID.Scan(r.PathValue("chirpID"))
 	if err != nil {
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		return
@@ -139,7 +360,25 @@ func (cfg *apiConfig) handleGetChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := json.Marshal(chirps)
+	hidden, err := cfg.hiddenUsers(context.Background(), viewer, []pgtype.UUID{chirps.UserID})
+	if err != nil {
+		log.Printf("Error getting hidden users from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if hidden[chirps.UserID] {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	responses, err := cfg.chirpResponses(context.Background(), viewer, []database.Chirp{chirps})
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses[0])
 	if err != nil {
 		log.Printf("Error marshalling chirp struct: %v\n", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -151,21 +390,12 @@ func (cfg *apiConfig) handleGetChirp(w http.ResponseWriter, r *http.Request) {
 
 func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, r *http.Request) {
 	// Validate access token
-	accessToken, err := auth.GetBearerToken(r.Header)
+	userID, err := cfg.authenticate(r)
 	if err != nil {
-		http.Error(w, "Access token is missing or invalid in the Authorization header", http.StatusUnauthorized)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
 		return
 	}
 
-	id, err := auth.ValidateJWT(accessToken, cfg.authSecret)
-	if err != nil {
-		http.Error(w, "Invalid access token", http.StatusUnauthorized)
-		return
-	}
-
-	userID := pgtype.UUID{}
-	userID.Scan(id.String())
-
 	// Parse chirp ID from the path
 	pgUUID := pgtype.UUID{}
 	err = pgUUID.Scan(r.PathValue("chirpID"))
@@ -188,6 +418,7 @@ func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, r *http.Request)
 
 	if chirp.UserID != userID {
 		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
 	}
 
 	// Remove chirp from database
@@ -197,6 +428,7 @@ func (cfg *apiConfig) handleDeleteChirp(w http.ResponseWriter, r *http.Request)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	cfg.publishEvent(context.Background(), eventChirpDeleted, chirpEvent{ID: chirp.ID, UserID: chirp.UserID})
 
 	w.WriteHeader(http.StatusNoContent)
 }
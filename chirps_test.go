@@ -0,0 +1,169 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testChirp decodes chirp responses; pgtype.Timestamp cannot unmarshal the
+// JSON it marshals, so only the fields under test are kept.
+type testChirp struct {
+	ID     string `json:"id"`
+	Body   string `json:"body"`
+	UserID string `json:"user_id"`
+}
+
+func (ts *testServer) createChirp(user testUser, body string) testChirp {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]string{"body": body})
+	require.Equal(ts.t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[testChirp](ts.t, rec)
+}
+
+func TestCreateChirp(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps", "", map[string]string{"body": "hello"})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Empty Body", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]string{"body": ""})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Created", func(t *testing.T) {
+		chirp := ts.createChirp(user, "hello")
+		assert.Equal(t, "hello", chirp.Body)
+		assert.Equal(t, user.ID, chirp.UserID)
+	})
+}
+
+func TestGetChirps(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	first := ts.createChirp(alice, "first")
+	second := ts.createChirp(bob, "second")
+
+	t.Run("All", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		chirps := decode[[]testChirp](t, rec)
+		require.Len(t, chirps, 2)
+		assert.Equal(t, first.ID, chirps[0].ID)
+	})
+
+	t.Run("Descending", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps?sort=desc", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		chirps := decode[[]testChirp](t, rec)
+		require.Len(t, chirps, 2)
+		assert.Equal(t, second.ID, chirps[0].ID)
+	})
+
+	t.Run("By Author", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps?author_id="+bob.ID, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		chirps := decode[[]testChirp](t, rec)
+		require.Len(t, chirps, 1)
+		assert.Equal(t, second.ID, chirps[0].ID)
+	})
+
+	t.Run("Single", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps/"+first.ID, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, first.Body, decode[testChirp](t, rec).Body)
+	})
+}
+
+func TestDeleteChirp(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	chirp := ts.createChirp(alice, "hello")
+	path := "/api/chirps/" + chirp.ID
+
+	rec := ts.do(http.MethodDelete, path, bob.bearer(), nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = ts.do(http.MethodDelete, path, alice.bearer(), nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = ts.do(http.MethodGet, path, "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestResetCascadesToChirps(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+	ts.createChirp(user, "hello")
+
+	rec := ts.do(http.MethodPost, "/admin/reset", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = ts.do(http.MethodGet, "/api/chirps", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, decode[[]testChirp](t, rec))
+}
+
+func TestGetChirpsPagination(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+
+	var created []string
+	for _, body := range []string{"one", "two", "three", "four", "five"} {
+		created = append(created, ts.createChirp(user, body).ID)
+	}
+
+	// collect follows X-Next-Cursor until the last page.
+	collect := func(t *testing.T, path string) []string {
+		var ids []string
+		for path != "" {
+			rec := ts.do(http.MethodGet, path, "", nil)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			chirps := decode[[]testChirp](t, rec)
+			require.LessOrEqual(t, len(chirps), 2)
+			for _, chirp := range chirps {
+				ids = append(ids, chirp.ID)
+			}
+
+			path = ""
+			if link := rec.Header().Get("Link"); link != "" {
+				assert.Contains(t, link, `rel="next"`)
+				path = strings.TrimPrefix(strings.Split(link, ">")[0], "<")
+			}
+		}
+		return ids
+	}
+
+	t.Run("Ascending", func(t *testing.T) {
+		assert.Equal(t, created, collect(t, "/api/chirps?limit=2"))
+	})
+
+	t.Run("Descending", func(t *testing.T) {
+		reversed := slices.Clone(created)
+		slices.Reverse(reversed)
+		assert.Equal(t, reversed, collect(t, "/api/chirps?limit=2&sort=desc&author_id="+user.ID))
+	})
+
+	t.Run("Invalid Cursor", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps?cursor=nope", "", nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid Limit", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps?limit=0", "", nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
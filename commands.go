@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chtozamm/chirpy/internal/database"
+)
+
+// runCommand executes a command-line subcommand instead of starting the
+// server.
+func runCommand(ctx context.Context, pool *database.Pool, args []string) error {
+	switch args[0] {
+	case "migrate":
+		provider, err := openMigrationProvider(pool)
+		if err != nil {
+			return err
+		}
+		return runMigrate(ctx, provider, args[1:])
+	case "backfill-hashtags":
+		return backfillHashtags(ctx, database.New(pool))
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// maxConversationParticipants includes the user starting the
+	// conversation.
+	maxConversationParticipants = 10
+	maxMessageLength            = 1000
+)
+
+type conversationParticipant struct {
+	publicProfile
+	LastReadAt pgtype.Timestamp `json:"last_read_at"`
+}
+
+type conversationResponse struct {
+	ID           pgtype.UUID               `json:"id"`
+	CreatedAt    pgtype.Timestamp          `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp          `json:"updated_at"`
+	IsGroup      bool                      `json:"is_group"`
+	Participants []conversationParticipant `json:"participants"`
+	UnreadCount  int64                     `json:"unread_count"`
+}
+
+// directKey identifies the one-to-one conversation between two users
+// regardless of who started it.
+func directKey(userID, otherID pgtype.UUID) pgtype.Text {
+	ids := []string{userID.String(), otherID.String()}
+	slices.Sort(ids)
+	return pgtype.Text{String: strings.Join(ids, ":"), Valid: true}
+}
+
+// conversationResponses adds the participants of each conversation and the
+// number of messages userID has not read.
+func (cfg *apiConfig) conversationResponses(ctx context.Context, userID pgtype.UUID, conversations []database.Conversation) ([]conversationResponse, error) {
+	responses := []conversationResponse{}
+	if len(conversations) == 0 {
+		return responses, nil
+	}
+
+	ids := make([]pgtype.UUID, len(conversations))
+	for i, conversation := range conversations {
+		ids[i] = conversation.ID
+	}
+
+	rows, err := cfg.db.GetConversationParticipants(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	participants := make(map[pgtype.UUID][]conversationParticipant)
+	for _, row := range rows {
+		participants[row.ConversationID] = append(participants[row.ConversationID], conversationParticipant{
+			publicProfile: newPublicProfile(row.User),
+			LastReadAt:    row.LastReadAt,
+		})
+	}
+
+	counts, err := cfg.db.GetUnreadCounts(ctx, database.GetUnreadCountsParams{UserID: userID, ConversationIds: ids})
+	if err != nil {
+		return nil, err
+	}
+	unread := make(map[pgtype.UUID]int64)
+	for _, count := range counts {
+		unread[count.ConversationID] = count.UnreadCount
+	}
+
+	for _, conversation := range conversations {
+		responses = append(responses, conversationResponse{
+			ID:           conversation.ID,
+			CreatedAt:    conversation.CreatedAt,
+			UpdatedAt:    conversation.UpdatedAt,
+			IsGroup:      !conversation.DirectKey.Valid,
+			Participants: participants[conversation.ID],
+			UnreadCount:  unread[conversation.ID],
+		})
+	}
+	return responses, nil
+}
+
+func (cfg *apiConfig) handleCreateConversation(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	type parameters struct {
+		Participants []string `json:"participants"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Participants are looked up one by one, so the list is bounded before
+	// any of them is. Each may name the caller as well as other users.
+	var participants []string
+	seen := make(map[string]bool)
+	for _, participant := range params.Participants {
+		if !seen[participant] {
+			seen[participant] = true
+			participants = append(participants, participant)
+		}
+	}
+	if len(participants) > maxConversationParticipants {
+		http.Error(w, fmt.Sprintf("conversations cannot have more than %d participants", maxConversationParticipants), http.StatusBadRequest)
+		return
+	}
+
+	ids := []pgtype.UUID{userID}
+	for _, participant := range participants {
+		user, err := cfg.lookupUser(context.Background(), participant)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				http.Error(w, fmt.Sprintf("unknown participant %q", participant), http.StatusBadRequest)
+				return
+			}
+			log.Printf("Error getting user from db: %v\n", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if slices.Contains(ids, user.ID) {
+			continue
+		}
+
+		blocked, err := cfg.db.IsBlocked(context.Background(), database.IsBlockedParams{UserID: userID, OtherID: user.ID})
+		if err != nil {
+			log.Printf("Error checking blocks: %v\n", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if blocked {
+			http.Error(w, fmt.Sprintf("you cannot message %q", participant), http.StatusForbidden)
+			return
+		}
+		ids = append(ids, user.ID)
+	}
+
+	if len(ids) < 2 {
+		http.Error(w, "participants must include another user", http.StatusBadRequest)
+		return
+	}
+	if len(ids) > maxConversationParticipants {
+		http.Error(w, fmt.Sprintf("conversations cannot have more than %d participants", maxConversationParticipants), http.StatusBadRequest)
+		return
+	}
+
+	// Two users share a single one-to-one conversation: starting it again
+	// returns the existing one.
+	createParams := database.CreateConversationParams{ParticipantIds: ids}
+	if len(ids) == 2 {
+		createParams.DirectKey = directKey(ids[0], ids[1])
+	}
+	status := http.StatusCreated
+	conversation, err := cfg.db.CreateConversation(context.Background(), createParams)
+	if errors.Is(err, pgx.ErrNoRows) && createParams.DirectKey.Valid {
+		status = http.StatusOK
+		conversation, err = cfg.db.GetDirectConversation(context.Background(), createParams.DirectKey)
+	}
+	if err != nil {
+		log.Printf("Error creating conversation: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	responses, err := cfg.conversationResponses(context.Background(), userID, []database.Conversation{conversation})
+	if err != nil {
+		log.Printf("Error getting conversation participants from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses[0])
+	if err != nil {
+		log.Printf("Error marshalling conversation struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleGetConversations(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "conversations are always sorted by latest activity", http.StatusBadRequest)
+		return
+	}
+
+	conversations, err := cfg.db.GetConversations(context.Background(), database.GetConversationsParams{
+		UserID:          userID,
+		CursorUpdatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting conversations from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	conversations, next := trimPage(conversations, page, func(conversation database.Conversation) pageCursor {
+		return newPageCursor(conversation.UpdatedAt, conversation.ID)
+	})
+
+	responses, err := cfg.conversationResponses(context.Background(), userID, conversations)
+	if err != nil {
+		log.Printf("Error getting conversation participants from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses)
+	if err != nil {
+		log.Printf("Error marshalling conversations struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+// ownConversation looks up a conversation of the caller in the request
+// path. Conversations the caller does not take part in are not found. It
+// writes the error response itself when ok is false.
+func (cfg *apiConfig) ownConversation(w http.ResponseWriter, r *http.Request) (userID pgtype.UUID, conversation database.Conversation, ok bool) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return userID, conversation, false
+	}
+
+	id := pgtype.UUID{}
+	err = id.Scan(r.PathValue("conversationID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return userID, conversation, false
+	}
+
+	conversation, err = cfg.db.GetConversation(context.Background(), database.GetConversationParams{ID: id, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return userID, conversation, false
+		}
+		log.Printf("Error getting conversation from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return userID, conversation, false
+	}
+	return userID, conversation, true
+}
+
+func (cfg *apiConfig) handleGetConversation(w http.ResponseWriter, r *http.Request) {
+	userID, conversation, ok := cfg.ownConversation(w, r)
+	if !ok {
+		return
+	}
+
+	responses, err := cfg.conversationResponses(context.Background(), userID, []database.Conversation{conversation})
+	if err != nil {
+		log.Printf("Error getting conversation participants from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses[0])
+	if err != nil {
+		log.Printf("Error marshalling conversation struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleSendMessage(w http.ResponseWriter, r *http.Request) {
+	userID, conversation, ok := cfg.ownConversation(w, r)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Body string `json:"body"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if strings.TrimSpace(params.Body) == "" {
+		http.Error(w, "body cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(params.Body) > maxMessageLength {
+		http.Error(w, fmt.Sprintf("body cannot be longer than %d characters", maxMessageLength), http.StatusBadRequest)
+		return
+	}
+
+	blocked, err := cfg.db.IsBlockedInConversation(context.Background(), database.IsBlockedInConversationParams{
+		UserID:         userID,
+		ConversationID: conversation.ID,
+	})
+	if err != nil {
+		log.Printf("Error checking blocks: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if blocked {
+		http.Error(w, "you cannot message a user you blocked or who blocked you", http.StatusForbidden)
+		return
+	}
+
+	message, err := cfg.db.CreateMessage(context.Background(), database.CreateMessageParams{
+		Body:           params.Body,
+		ConversationID: conversation.ID,
+		UserID:         userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error creating message: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Error marshalling message struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleGetMessages(w http.ResponseWriter, r *http.Request) {
+	_, conversation, ok := cfg.ownConversation(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "messages are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	messages, err := cfg.db.GetMessages(context.Background(), database.GetMessagesParams{
+		ConversationID:  conversation.ID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting messages from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	messages, next := trimPage(messages, page, func(message database.Message) pageCursor {
+		return newPageCursor(message.CreatedAt, message.ID)
+	})
+
+	resp, err := json.Marshal(messages)
+	if err != nil {
+		log.Printf("Error marshalling messages struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleMarkConversationRead(w http.ResponseWriter, r *http.Request) {
+	userID, conversation, ok := cfg.ownConversation(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.MarkConversationRead(context.Background(), database.MarkConversationReadParams{
+		ConversationID: conversation.ID,
+		UserID:         userID,
+	})
+	if err != nil {
+		log.Printf("Error marking conversation read: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	count, err := cfg.db.CountUnreadMessages(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error counting unread messages: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	type response struct {
+		UnreadCount int64 `json:"unread_count"`
+	}
+
+	resp, err := json.Marshal(response{UnreadCount: count})
+	if err != nil {
+		log.Printf("Error marshalling response struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
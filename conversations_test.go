@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConversation struct {
+	ID           string `json:"id"`
+	IsGroup      bool   `json:"is_group"`
+	UnreadCount  int64  `json:"unread_count"`
+	Participants []struct {
+		ID         string  `json:"id"`
+		LastReadAt *string `json:"last_read_at"`
+	} `json:"participants"`
+}
+
+type testMessage struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Body   string `json:"body"`
+}
+
+func (ts *testServer) startConversation(user testUser, participants ...testUser) testConversation {
+	ts.t.Helper()
+
+	var ids []string
+	for _, participant := range participants {
+		ids = append(ids, participant.ID)
+	}
+	rec := ts.do(http.MethodPost, "/api/conversations", user.bearer(), map[string][]string{"participants": ids})
+	require.Contains(ts.t, []int{http.StatusCreated, http.StatusOK}, rec.Code, rec.Body.String())
+	return decode[testConversation](ts.t, rec)
+}
+
+func (ts *testServer) sendMessage(user testUser, conversation testConversation, body string) testMessage {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/conversations/"+conversation.ID+"/messages", user.bearer(), map[string]string{"body": body})
+	require.Equal(ts.t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[testMessage](ts.t, rec)
+}
+
+func (ts *testServer) unreadMessages(t *testing.T, user testUser) int64 {
+	t.Helper()
+
+	rec := ts.do(http.MethodGet, "/api/conversations/unread", user.bearer(), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	return decode[map[string]int64](t, rec)["unread_count"]
+}
+
+func TestConversations(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	carol := ts.signup("carol@example.com")
+
+	direct := ts.startConversation(alice, bob)
+
+	t.Run("Create", func(t *testing.T) {
+		assert.False(t, direct.IsGroup)
+		require.Len(t, direct.Participants, 2)
+
+		rec := ts.do(http.MethodPost, "/api/conversations", "", map[string][]string{"participants": {bob.ID}})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec = ts.do(http.MethodPost, "/api/conversations", alice.bearer(), map[string][]string{"participants": {alice.ID}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = ts.do(http.MethodPost, "/api/conversations", alice.bearer(), map[string][]string{"participants": {"nobody"}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("One Direct Conversation Per Pair", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/conversations", bob.bearer(), map[string][]string{"participants": {alice.ID}})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, direct.ID, decode[testConversation](t, rec).ID)
+	})
+
+	t.Run("Too Many Participants", func(t *testing.T) {
+		var ids []string
+		for i := range maxConversationParticipants {
+			ids = append(ids, ts.signup("user"+strings.Repeat("x", i)+"@example.com").ID)
+		}
+		rec := ts.do(http.MethodPost, "/api/conversations", alice.bearer(), map[string][]string{"participants": ids})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		// Long lists are rejected before any participant is looked up.
+		ids = nil
+		for i := range 1000 {
+			ids = append(ids, fmt.Sprintf("nobody%d", i))
+		}
+		rec = ts.do(http.MethodPost, "/api/conversations", alice.bearer(), map[string][]string{"participants": ids})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "cannot have more than")
+	})
+
+	t.Run("Messages And Unread Counts", func(t *testing.T) {
+		first := ts.sendMessage(alice, direct, "hi bob")
+		second := ts.sendMessage(alice, direct, "are you there?")
+		assert.Equal(t, alice.ID, first.UserID)
+		assert.Equal(t, int64(2), ts.unreadMessages(t, bob))
+		assert.Equal(t, int64(0), ts.unreadMessages(t, alice))
+
+		rec := ts.do(http.MethodGet, "/api/conversations/"+direct.ID+"/messages?limit=1", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		messages := decode[[]testMessage](t, rec)
+		require.Len(t, messages, 1)
+		assert.Equal(t, second.ID, messages[0].ID)
+		next := rec.Header().Get("X-Next-Cursor")
+		require.NotEmpty(t, next)
+		rec = ts.do(http.MethodGet, "/api/conversations/"+direct.ID+"/messages?cursor="+next, bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []testMessage{first}, decode[[]testMessage](t, rec))
+
+		rec = ts.do(http.MethodGet, "/api/conversations", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		conversations := decode[[]testConversation](t, rec)
+		require.Len(t, conversations, 1)
+		assert.Equal(t, int64(2), conversations[0].UnreadCount)
+
+		rec = ts.do(http.MethodPost, "/api/conversations/"+direct.ID+"/read", bob.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, int64(0), ts.unreadMessages(t, bob))
+
+		// Read markers are visible to the other participants.
+		rec = ts.do(http.MethodGet, "/api/conversations/"+direct.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		for _, participant := range decode[testConversation](t, rec).Participants {
+			assert.NotNil(t, participant.LastReadAt, participant.ID)
+		}
+
+		rec = ts.do(http.MethodPost, "/api/conversations/"+direct.ID+"/messages", bob.bearer(), map[string]string{"body": " "})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = ts.do(http.MethodPost, "/api/conversations/"+direct.ID+"/messages", bob.bearer(), map[string]string{"body": strings.Repeat("a", maxMessageLength+1)})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Participants Only", func(t *testing.T) {
+		for _, path := range []string{"", "/messages"} {
+			rec := ts.do(http.MethodGet, "/api/conversations/"+direct.ID+path, carol.bearer(), nil)
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+		rec := ts.do(http.MethodPost, "/api/conversations/"+direct.ID+"/messages", carol.bearer(), map[string]string{"body": "let me in"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = ts.do(http.MethodGet, "/api/conversations/"+direct.ID+"/messages", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		group := ts.startConversation(carol, alice, bob)
+		assert.True(t, group.IsGroup)
+		assert.Len(t, group.Participants, 3)
+		assert.NotEqual(t, group.ID, ts.startConversation(carol, alice, bob).ID)
+
+		ts.sendMessage(carol, group, "hello both")
+		assert.Equal(t, int64(1), ts.unreadMessages(t, alice))
+
+		// The inbox is ordered by the latest message.
+		rec := ts.do(http.MethodGet, "/api/conversations", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		conversations := decode[[]testConversation](t, rec)
+		require.Len(t, conversations, 3)
+		assert.Equal(t, group.ID, conversations[0].ID)
+	})
+
+	t.Run("Blocked Users", func(t *testing.T) {
+		dave := ts.signup("dave@example.com")
+		conversation := ts.startConversation(dave, alice)
+		ts.block(alice, dave)
+
+		rec := ts.do(http.MethodPost, "/api/conversations/"+conversation.ID+"/messages", dave.bearer(), map[string]string{"body": "hello?"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		rec = ts.do(http.MethodPost, "/api/conversations", dave.bearer(), map[string][]string{"participants": {alice.ID, bob.ID}})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
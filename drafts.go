@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// draftParameters is the content of a draft. Unlike a chirp, a draft may be
+// empty, and the chirps it quotes or replies to are only checked when it is
+// published.
+type draftParameters struct {
+	Body      string `json:"body"`
+	QuoteOf   string `json:"quote_of"`
+	InReplyTo string `json:"in_reply_to"`
+}
+
+// parseOptionalUUID parses s as a UUID, returning an invalid UUID for an
+// empty string.
+func parseOptionalUUID(s string) (pgtype.UUID, error) {
+	id := pgtype.UUID{}
+	if s == "" {
+		return id, nil
+	}
+	err := id.Scan(s)
+	return id, err
+}
+
+// optionalUUIDString formats id, returning an empty string for an invalid
+// UUID.
+func optionalUUIDString(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return id.String()
+}
+
+// decodeDraft reads the content of a draft from a request and returns the
+// parameters to create it, without the user ID. It writes the error response
+// itself when ok is false.
+func decodeDraft(w http.ResponseWriter, r *http.Request) (draft database.CreateDraftParams, ok bool) {
+	decoder := json.NewDecoder(r.Body)
+	params := draftParameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return draft, false
+	}
+
+	draft.Body = params.Body
+	draft.QuoteOf, err = parseOptionalUUID(params.QuoteOf)
+	if err != nil {
+		http.Error(w, "invalid quote_of", http.StatusBadRequest)
+		return draft, false
+	}
+	draft.InReplyTo, err = parseOptionalUUID(params.InReplyTo)
+	if err != nil {
+		http.Error(w, "invalid in_reply_to", http.StatusBadRequest)
+		return draft, false
+	}
+	return draft, true
+}
+
+func (cfg *apiConfig) handleCreateDraft(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	params, ok := decodeDraft(w, r)
+	if !ok {
+		return
+	}
+	params.UserID = userID
+
+	draft, err := cfg.db.CreateDraft(context.Background(), params)
+	if err != nil {
+		log.Printf("Error creating draft: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(draft)
+	if err != nil {
+		log.Printf("Error marshalling draft struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleGetDrafts(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "drafts can only be sorted desc", http.StatusBadRequest)
+		return
+	}
+
+	drafts, err := cfg.db.GetDrafts(context.Background(), database.GetDraftsParams{
+		UserID:          userID,
+		CursorUpdatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting drafts from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	drafts, next := trimPage(drafts, page, func(draft database.Draft) pageCursor {
+		return newPageCursor(draft.UpdatedAt, draft.ID)
+	})
+
+	resp, err := json.Marshal(drafts)
+	if err != nil {
+		log.Printf("Error marshalling drafts struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+// ownDraft looks up the caller's draft in the request path. Other users'
+// drafts are not found. It writes the error response itself when ok is
+// false.
+func (cfg *apiConfig) ownDraft(w http.ResponseWriter, r *http.Request) (draft database.Draft, ok bool) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return database.Draft{}, false
+	}
+
+	id := pgtype.UUID{}
+	err = id.Scan(r.PathValue("draftID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return database.Draft{}, false
+	}
+
+	draft, err = cfg.db.GetDraft(context.Background(), database.GetDraftParams{ID: id, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return database.Draft{}, false
+		}
+		log.Printf("Error getting draft from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return database.Draft{}, false
+	}
+	return draft, true
+}
+
+func (cfg *apiConfig) handleGetDraft(w http.ResponseWriter, r *http.Request) {
+	draft, ok := cfg.ownDraft(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := json.Marshal(draft)
+	if err != nil {
+		log.Printf("Error marshalling draft struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
+
+// handleUpdateDraft replaces the content of a draft.
+func (cfg *apiConfig) handleUpdateDraft(w http.ResponseWriter, r *http.Request) {
+	draft, ok := cfg.ownDraft(w, r)
+	if !ok {
+		return
+	}
+
+	params, ok := decodeDraft(w, r)
+	if !ok {
+		return
+	}
+
+	draft, err := cfg.db.UpdateDraft(context.Background(), database.UpdateDraftParams{
+		Body:      params.Body,
+		QuoteOf:   params.QuoteOf,
+		InReplyTo: params.InReplyTo,
+		ID:        draft.ID,
+		UserID:    draft.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error updating draft: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(draft)
+	if err != nil {
+		log.Printf("Error marshalling draft struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	draft, ok := cfg.ownDraft(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := cfg.db.DeleteDraft(context.Background(), database.DeleteDraftParams{ID: draft.ID, UserID: draft.UserID})
+	if err != nil {
+		log.Printf("Error deleting draft: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePublishDraft validates a draft like POST /api/chirps validates a new
+// chirp, then replaces the draft with the chirp.
+func (cfg *apiConfig) handlePublishDraft(w http.ResponseWriter, r *http.Request) {
+	draft, ok := cfg.ownDraft(w, r)
+	if !ok {
+		return
+	}
+
+	if draft.Body == "" {
+		http.Error(w, "body cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	chirpParams, err := cfg.prepareChirp(context.Background(), draft.UserID, draft.Body, optionalUUIDString(draft.QuoteOf), optionalUUIDString(draft.InReplyTo))
+	if err != nil {
+		var inputErr chirpInputError
+		if errors.As(err, &inputErr) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Error preparing chirp: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	newChirp, err := cfg.db.PublishDraft(context.Background(), database.PublishDraftParams{
+		DraftID:        draft.ID,
+		UserID:         draft.UserID,
+		Body:           chirpParams.Body,
+		QuoteOf:        chirpParams.QuoteOf,
+		InReplyTo:      chirpParams.InReplyTo,
+		ConversationID: chirpParams.ConversationID,
+		AncestorIds:    chirpParams.AncestorIds,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error publishing draft: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	cfg.afterChirpCreated(context.Background(), newChirp)
+
+	responses, err := cfg.chirpResponses(context.Background(), draft.UserID, []database.Chirp{newChirp})
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses[0])
+	if err != nil {
+		log.Printf("Error marshalling chirp struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDraft struct {
+	ID        string  `json:"id"`
+	Body      string  `json:"body"`
+	QuoteOf   *string `json:"quote_of"`
+	InReplyTo *string `json:"in_reply_to"`
+}
+
+func (ts *testServer) createDraft(user testUser, draft map[string]string) testDraft {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/drafts", user.bearer(), draft)
+	require.Equal(ts.t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[testDraft](ts.t, rec)
+}
+
+func TestDrafts(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	draft := ts.createDraft(alice, map[string]string{"body": ""})
+
+	t.Run("Invalid Reference", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/drafts", alice.bearer(), map[string]string{"quote_of": "not-a-uuid"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		rec := ts.do(http.MethodPut, "/api/drafts/"+draft.ID, alice.bearer(), map[string]string{"body": "almost done"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "almost done", decode[testDraft](t, rec).Body)
+
+		rec = ts.do(http.MethodGet, "/api/drafts/"+draft.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "almost done", decode[testDraft](t, rec).Body)
+	})
+
+	t.Run("List Most Recently Edited First", func(t *testing.T) {
+		other := ts.createDraft(alice, map[string]string{"body": "other"})
+		rec := ts.do(http.MethodPut, "/api/drafts/"+draft.ID, alice.bearer(), map[string]string{"body": "edited"})
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/drafts", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		drafts := decode[[]testDraft](t, rec)
+		require.Len(t, drafts, 2)
+		assert.Equal(t, draft.ID, drafts[0].ID)
+		assert.Equal(t, other.ID, drafts[1].ID)
+	})
+
+	t.Run("Private", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/drafts", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, decode[[]testDraft](t, rec))
+
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+			rec := ts.do(method, "/api/drafts/"+draft.ID, bob.bearer(), map[string]string{"body": "mine"})
+			assert.Equal(t, http.StatusNotFound, rec.Code, method)
+		}
+		rec = ts.do(http.MethodPost, "/api/drafts/"+draft.ID+"/publish", bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		assert.Empty(t, ts.chirpIDs(t, alice, "/api/chirps"), "drafts are not chirps")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		doomed := ts.createDraft(alice, map[string]string{"body": "never mind"})
+		rec := ts.do(http.MethodDelete, "/api/drafts/"+doomed.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/drafts/"+doomed.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestPublishDraft(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	t.Run("Empty Body", func(t *testing.T) {
+		draft := ts.createDraft(alice, map[string]string{"body": ""})
+		rec := ts.do(http.MethodPost, "/api/drafts/"+draft.ID+"/publish", alice.bearer(), nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Deleted Parent", func(t *testing.T) {
+		parent := ts.createChirp(bob, "soon gone")
+		draft := ts.createDraft(alice, map[string]string{"body": "a reply", "in_reply_to": parent.ID})
+		rec := ts.do(http.MethodDelete, "/api/chirps/"+parent.ID, bob.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodPost, "/api/drafts/"+draft.ID+"/publish", alice.bearer(), nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = ts.do(http.MethodGet, "/api/drafts/"+draft.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusOK, rec.Code, "a draft that fails to publish is kept")
+	})
+
+	t.Run("Blocked Parent", func(t *testing.T) {
+		parent := ts.createChirp(bob, "hello")
+		draft := ts.createDraft(alice, map[string]string{"body": "a quote", "quote_of": parent.ID})
+		ts.block(bob, alice)
+		t.Cleanup(func() { ts.do(http.MethodDelete, "/api/users/"+alice.ID+"/block", bob.bearer(), nil) })
+
+		rec := ts.do(http.MethodPost, "/api/drafts/"+draft.ID+"/publish", alice.bearer(), nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Reply", func(t *testing.T) {
+		parent := ts.createChirp(bob, "what do you think?")
+		draft := ts.createDraft(alice, map[string]string{"body": "I agree #drafts", "in_reply_to": parent.ID})
+
+		rec := ts.do(http.MethodPost, "/api/drafts/"+draft.ID+"/publish", alice.bearer(), nil)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		chirp := decode[map[string]any](t, rec)
+		assert.Equal(t, "I agree #drafts", chirp["body"])
+		assert.Equal(t, parent.ID, chirp["in_reply_to"])
+		assert.Equal(t, parent.ID, chirp["conversation_id"])
+
+		assert.Contains(t, ts.chirpIDs(t, alice, "/api/hashtags/drafts/chirps"), chirp["id"])
+		rec = ts.do(http.MethodGet, "/api/drafts/"+draft.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Published Once", func(t *testing.T) {
+		draft := ts.createDraft(alice, map[string]string{"body": "only once"})
+
+		var wg sync.WaitGroup
+		codes := make([]int, 5)
+		for i := range codes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				codes[i] = ts.do(http.MethodPost, "/api/drafts/"+draft.ID+"/publish", alice.bearer(), nil).Code
+			}()
+		}
+		wg.Wait()
+
+		published := 0
+		for _, code := range codes {
+			if code == http.StatusCreated {
+				published++
+			}
+		}
+		assert.Equal(t, 1, published)
+	})
+}
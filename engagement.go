@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// chirpEngagement is how others interacted with a chirp, as seen by the
+// viewer.
+type chirpEngagement struct {
+	LikeCount      int64 `json:"like_count"`
+	LikedByMe      bool  `json:"liked_by_me"`
+	RechirpCount   int64 `json:"rechirp_count"`
+	RechirpedByMe  bool  `json:"rechirped_by_me"`
+	ReplyCount     int64 `json:"reply_count"`
+	BookmarkedByMe bool  `json:"bookmarked_by_me"`
+}
+
+// chirpResponse is a chirp as the API returns it. Rechirps and quotes embed
+// the chirp they refer to as Original; a quoted chirp that has been deleted
+// is embedded as a tombstone with only its ID and Deleted set, as are deleted
+// chirps in threads. Chirps by users hidden from the viewer by a block or
+// mute are embedded the same way with Unavailable set.
+type chirpResponse struct {
+	database.Chirp
+	chirpEngagement
+	Media       []mediaResponse `json:"media,omitempty"`
+	Poll        *pollResponse   `json:"poll,omitempty"`
+	Original    *chirpResponse  `json:"original,omitempty"`
+	Deleted     bool            `json:"deleted,omitempty"`
+	Unavailable bool            `json:"unavailable,omitempty"`
+	Pinned      bool            `json:"pinned,omitempty"`
+}
+
+func deletedChirpResponse(id pgtype.UUID) chirpResponse {
+	return chirpResponse{Chirp: database.Chirp{ID: id}, Deleted: true}
+}
+
+func unavailableChirpResponse(id pgtype.UUID) chirpResponse {
+	return chirpResponse{Chirp: database.Chirp{ID: id}, Unavailable: true}
+}
+
+// originalID returns the chirp that chirp rechirps or quotes, if any.
+func originalID(chirp database.Chirp) (pgtype.UUID, bool) {
+	if chirp.RechirpOf.Valid {
+		return chirp.RechirpOf, true
+	}
+	return chirp.QuoteOf, chirp.QuoteOf.Valid
+}
+
+// chirpResponses builds the responses for chirps with a fixed number of
+// queries however many chirps there are: two for the embedded originals and
+// whether the viewer may see them, one per kind of engagement, one for the
+// attached media and up to three for polls. viewer is
+// invalid for anonymous requests. The chirps themselves must already be
+// visible to viewer.
+func (cfg *apiConfig) chirpResponses(ctx context.Context, viewer pgtype.UUID, chirps []database.Chirp) ([]chirpResponse, error) {
+	responses := []chirpResponse{}
+	if len(chirps) == 0 {
+		return responses, nil
+	}
+
+	var originalIDs []pgtype.UUID
+	for _, chirp := range chirps {
+		if id, ok := originalID(chirp); ok {
+			originalIDs = append(originalIDs, id)
+		}
+	}
+
+	originals := make(map[pgtype.UUID]database.Chirp)
+	unavailable := make(map[pgtype.UUID]bool)
+	if len(originalIDs) > 0 {
+		rows, err := cfg.db.GetChirpsByIDs(ctx, originalIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		authors := make([]pgtype.UUID, len(rows))
+		for i, original := range rows {
+			authors[i] = original.UserID
+		}
+		hidden, err := cfg.hiddenUsers(ctx, viewer, authors)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, original := range rows {
+			if hidden[original.UserID] {
+				unavailable[original.ID] = true
+				continue
+			}
+			originals[original.ID] = original
+		}
+	}
+
+	ids := make([]pgtype.UUID, 0, len(chirps)+len(originals))
+	for _, chirp := range chirps {
+		ids = append(ids, chirp.ID)
+	}
+	for id := range originals {
+		ids = append(ids, id)
+	}
+
+	engagement, err := cfg.chirpEngagement(ctx, viewer, ids)
+	if err != nil {
+		return nil, err
+	}
+	media, err := cfg.chirpMedia(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	polls, err := cfg.chirpPolls(ctx, viewer, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, chirp := range chirps {
+		response := chirpResponse{Chirp: chirp, chirpEngagement: engagement[chirp.ID], Media: media[chirp.ID], Poll: polls[chirp.ID]}
+		if id, ok := originalID(chirp); ok {
+			if original, ok := originals[id]; ok {
+				response.Original = &chirpResponse{Chirp: original, chirpEngagement: engagement[id], Media: media[id], Poll: polls[id]}
+			} else if unavailable[id] {
+				tombstone := unavailableChirpResponse(id)
+				response.Original = &tombstone
+			} else {
+				tombstone := deletedChirpResponse(id)
+				response.Original = &tombstone
+			}
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// chirpEngagement looks up the engagement of the chirps with the given IDs.
+func (cfg *apiConfig) chirpEngagement(ctx context.Context, viewer pgtype.UUID, ids []pgtype.UUID) (map[pgtype.UUID]chirpEngagement, error) {
+	engagement := make(map[pgtype.UUID]chirpEngagement, len(ids))
+	update := func(id pgtype.UUID, f func(*chirpEngagement)) {
+		e := engagement[id]
+		f(&e)
+		engagement[id] = e
+	}
+
+	likeCounts, err := cfg.db.GetLikeCounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range likeCounts {
+		update(row.ChirpID, func(e *chirpEngagement) { e.LikeCount = row.LikeCount })
+	}
+
+	rechirpCounts, err := cfg.db.GetRechirpCounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rechirpCounts {
+		update(row.ChirpID, func(e *chirpEngagement) { e.RechirpCount = row.RechirpCount })
+	}
+
+	replyCounts, err := cfg.db.GetReplyCounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range replyCounts {
+		update(row.ChirpID, func(e *chirpEngagement) { e.ReplyCount = row.ReplyCount })
+	}
+
+	if !viewer.Valid {
+		return engagement, nil
+	}
+
+	liked, err := cfg.db.GetLikedChirpIDs(ctx, database.GetLikedChirpIDsParams{UserID: viewer, ChirpIds: ids})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range liked {
+		update(id, func(e *chirpEngagement) { e.LikedByMe = true })
+	}
+
+	rechirped, err := cfg.db.GetRechirpedChirpIDs(ctx, database.GetRechirpedChirpIDsParams{UserID: viewer, ChirpIds: ids})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range rechirped {
+		update(id, func(e *chirpEngagement) { e.RechirpedByMe = true })
+	}
+
+	bookmarked, err := cfg.db.GetBookmarkedChirpIDs(ctx, database.GetBookmarkedChirpIDsParams{UserID: viewer, ChirpIds: ids})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range bookmarked {
+		update(id, func(e *chirpEngagement) { e.BookmarkedByMe = true })
+	}
+
+	return engagement, nil
+}
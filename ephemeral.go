@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	minExpiresIn  = time.Minute
+	maxExpiresIn  = 30 * 24 * time.Hour
+	reapBatchSize = 100
+)
+
+// parseExpiresIn turns the lifetime of an ephemeral chirp, in seconds, into
+// its expiry time. The error is meant for the client.
+func parseExpiresIn(seconds int64) (pgtype.Timestamp, error) {
+	if seconds < int64(minExpiresIn/time.Second) {
+		return pgtype.Timestamp{}, errors.New("expires_in must be at least 60 seconds")
+	}
+	if seconds > int64(maxExpiresIn/time.Second) {
+		return pgtype.Timestamp{}, errors.New("expires_in cannot be more than 30 days")
+	}
+	expiresAt := time.Now().UTC().Add(time.Duration(seconds) * time.Second)
+	return pgtype.Timestamp{Time: expiresAt, Valid: true}, nil
+}
+
+// reapExpiredChirps deletes expired chirps in batches, so that a backlog
+// does not hold locks on many rows at once. Until then, queries already hide
+// them.
+func (cfg *apiConfig) reapExpiredChirps(ctx context.Context) error {
+	for {
+		deleted, err := cfg.db.DeleteExpiredChirps(ctx, reapBatchSize)
+		if err != nil {
+			return err
+		}
+		if deleted < reapBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEphemeralChirp(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+
+	t.Run("Expires In", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]any{"body": "gone in an hour", "expires_in": 3600})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		chirp := decode[struct {
+			ID        string  `json:"id"`
+			ExpiresAt *string `json:"expires_at"`
+		}](t, rec)
+		require.NotNil(t, chirp.ExpiresAt)
+		expiresAt, err := time.Parse("2006-01-02T15:04:05", (*chirp.ExpiresAt)[:19])
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().UTC().Add(time.Hour), expiresAt, time.Minute)
+		assert.Equal(t, []string{chirp.ID}, ts.chirpIDs(t, user, "/api/chirps"))
+	})
+
+	t.Run("Out Of Range", func(t *testing.T) {
+		for _, seconds := range []int64{0, 59, int64(maxExpiresIn/time.Second) + 1} {
+			rec := ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]any{"body": "when?", "expires_in": seconds})
+			assert.Equal(t, http.StatusBadRequest, rec.Code, seconds)
+		}
+	})
+
+	t.Run("Not Scheduled", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]any{
+			"body":       "later, briefly",
+			"publish_at": time.Now().Add(time.Hour),
+			"expires_in": 3600,
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestExpiredChirps(t *testing.T) {
+	ctx := context.Background()
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	kept := ts.createChirp(alice, "still here")
+
+	// Chirps cannot expire right away through the API.
+	expired, err := ts.store.CreateChirp(ctx, database.CreateChirpParams{
+		Body:      "#gone",
+		UserID:    ts.userID(alice),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(-time.Second).UTC(), Valid: true},
+	})
+	require.NoError(t, err)
+	require.NoError(t, indexHashtags(ctx, ts.store, expired))
+	_, err = ts.store.CreateLike(ctx, database.CreateLikeParams{UserID: ts.userID(bob), ChirpID: expired.ID})
+	require.NoError(t, err)
+	_, err = ts.store.CreateBookmark(ctx, database.CreateBookmarkParams{UserID: ts.userID(bob), ChirpID: expired.ID})
+	require.NoError(t, err)
+	expiredID := expired.ID.String()
+
+	t.Run("Invisible Before Reaping", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps/"+expiredID, bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, []string{kept.ID}, ts.chirpIDs(t, bob, "/api/chirps"))
+		assert.Equal(t, []string{kept.ID}, ts.chirpIDs(t, bob, "/api/chirps?author_id="+alice.ID))
+		assert.Empty(t, ts.chirpIDs(t, bob, "/api/hashtags/gone/chirps"))
+		assert.Empty(t, ts.chirpIDs(t, bob, "/api/bookmarks"))
+
+		rec = ts.do(http.MethodPost, "/api/chirps/"+expiredID+"/like", bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Reaped", func(t *testing.T) {
+		require.NoError(t, ts.cfg.reapExpiredChirps(ctx))
+
+		counts, err := ts.store.GetLikeCounts(ctx, []pgtype.UUID{expired.ID})
+		require.NoError(t, err)
+		assert.Empty(t, counts)
+		ids, err := ts.store.GetBookmarkedChirpIDs(ctx, database.GetBookmarkedChirpIDsParams{UserID: ts.userID(bob), ChirpIds: []pgtype.UUID{expired.ID}})
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+
+		rec := ts.do(http.MethodGet, "/api/chirps/"+kept.ID, bob.bearer(), nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
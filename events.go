@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"github.com/chtozamm/chirpy/internal/eventbus"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// eventBusChannel is the Postgres channel events are sent on.
+	eventBusChannel = "chirpy_events"
+	// eventIDSequence is the Postgres sequence events are numbered with.
+	eventIDSequence = "event_ids"
+)
+
+const (
+	eventChirpCreated = "chirp_created"
+	eventChirpDeleted = "chirp_deleted"
+	eventUserCreated  = "user_created"
+	eventUserUpdated  = "user_updated"
+	eventUserUpgraded = "user_upgraded"
+	eventUsersReset   = "users_reset"
+)
+
+// chirpEvent is the payload of chirp events. Events carry IDs rather than
+// the chirp itself to stay within the NOTIFY payload limit.
+type chirpEvent struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+}
+
+// userEvent is the payload of user events.
+type userEvent struct {
+	ID pgtype.UUID `json:"id"`
+}
+
+// publishEvent tells every server about a change. Like notifications, events
+// are a side effect of the change, so a failure is logged rather than
+// failing the request.
+func (cfg *apiConfig) publishEvent(ctx context.Context, kind string, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Error marshalling %s event: %v\n", kind, err)
+		return
+	}
+	err = cfg.events.Publish(ctx, eventbus.Event{Type: kind, Data: payload})
+	if err != nil {
+		log.Printf("Error publishing %s event: %v\n", kind, err)
+	}
+}
+
+// handleEvent applies an event published by any server, including this one,
+// to the in-memory state of this server.
+func (cfg *apiConfig) handleEvent(event eventbus.Event) {
+	switch event.Type {
+	case eventChirpCreated:
+		var data chirpEvent
+		err := json.Unmarshal(event.Data, &data)
+		if err != nil {
+			log.Printf("Error decoding %s event: %v\n", event.Type, err)
+			return
+		}
+		chirp, err := cfg.db.GetChirp(context.Background(), data.ID)
+		if err != nil {
+			// A chirp that is already gone is not worth streaming.
+			if !errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("Error getting chirp %v from db: %v\n", data.ID, err)
+			}
+			return
+		}
+		cfg.streamChirpCreated(context.Background(), event.ID, chirp)
+	case eventChirpDeleted:
+		var data chirpEvent
+		err := json.Unmarshal(event.Data, &data)
+		if err != nil {
+			log.Printf("Error decoding %s event: %v\n", event.Type, err)
+			return
+		}
+		cfg.stream.publish(streamEvent{ID: event.ID, Type: streamEventChirpDeleted, UserID: data.UserID, Data: event.Data})
+	case eventUserCreated, eventUserUpdated, eventUserUpgraded:
+		var data userEvent
+		err := json.Unmarshal(event.Data, &data)
+		if err != nil {
+			log.Printf("Error decoding %s event: %v\n", event.Type, err)
+			return
+		}
+		user, err := cfg.db.GetUserByID(context.Background(), data.ID)
+		if err != nil {
+			// Like chirps, users that are already gone are not streamed.
+			if !errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("Error getting user %v from db: %v\n", data.ID, err)
+			}
+			return
+		}
+		kind := streamEventUserUpdated
+		if event.Type == eventUserCreated {
+			kind = streamEventUserCreated
+		}
+		cfg.streamUser(event.ID, kind, user)
+	case eventUsersReset:
+		// The shared count was reset; hits not flushed yet predate it.
+		cfg.fileserverHits.Store(0)
+	}
+}
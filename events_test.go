@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/chtozamm/chirpy/internal/eventbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvents(t *testing.T) {
+	ts := newTestServer(t)
+	var events []string
+	ts.cfg.events.Subscribe(func(event eventbus.Event) { events = append(events, event.Type) })
+
+	alice := ts.signup("alice@example.com")
+	chirp := ts.createChirp(alice, "hello")
+	rec := ts.do(http.MethodDelete, "/api/chirps/"+chirp.ID, alice.bearer(), nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []string{eventUserCreated, eventChirpCreated, eventChirpDeleted}, events)
+
+	t.Run("Events From Other Servers", func(t *testing.T) {
+		server := httptest.NewServer(ts.mux)
+		t.Cleanup(server.Close)
+		_, next := openStream(t, server, "/api/stream", nil)
+		var published uint64
+		ts.cfg.events.Subscribe(func(event eventbus.Event) { published = event.ID })
+
+		// A chirp created through another server is only known from its
+		// event; this server streams it all the same.
+		created, err := ts.store.CreateChirp(context.Background(), database.CreateChirpParams{Body: "from elsewhere", UserID: ts.userID(alice)})
+		require.NoError(t, err)
+		data, err := json.Marshal(chirpEvent{ID: created.ID, UserID: created.UserID})
+		require.NoError(t, err)
+		require.NoError(t, ts.cfg.events.Publish(context.Background(), eventbus.Event{Type: eventChirpCreated, Data: data}))
+
+		event := next()
+		assert.Equal(t, streamEventChirpCreated, event.Event)
+		assert.Contains(t, event.Data, "from elsewhere")
+		assert.Equal(t, strconv.FormatUint(published, 10), event.ID, "the stream uses the ID of the bus event")
+	})
+
+	t.Run("User Events", func(t *testing.T) {
+		server := httptest.NewServer(ts.mux)
+		t.Cleanup(server.Close)
+		ts.waitForStreamSubscribers(t, 0)
+		_, next := openStream(t, server, "/api/stream?author_id="+alice.ID, nil)
+		ts.waitForStreamSubscribers(t, 1)
+
+		ts.signup("bob@example.com")
+		rec := ts.do(http.MethodPut, "/api/users", alice.bearer(), map[string]string{"display_name": "Alice"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		event := next()
+		assert.Equal(t, streamEventUserUpdated, event.Event, "only the events of alice are streamed")
+		assert.Contains(t, event.Data, `"display_name":"Alice"`)
+
+		upgrade := map[string]any{"event": "user.upgraded", "data": map[string]string{"user_id": alice.ID}}
+		rec = ts.do(http.MethodPost, "/api/polka/webhooks", "ApiKey "+ts.cfg.polkaKey, upgrade)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		event = next()
+		assert.Equal(t, streamEventUserUpdated, event.Event)
+		assert.Contains(t, event.Data, `"is_chirpy_red":true`)
+		assert.Equal(t, []string{eventUserCreated, eventUserUpdated, eventUserUpgraded}, events[len(events)-3:])
+	})
+
+	t.Run("Reset Hits", func(t *testing.T) {
+		ts.cfg.fileserverHits.Store(3)
+		require.NoError(t, ts.cfg.events.Publish(context.Background(), eventbus.Event{Type: eventUsersReset, Data: json.RawMessage(`{}`)}))
+		assert.Zero(t, ts.cfg.fileserverHits.Load())
+	})
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// userTarget authenticates the request and looks up the user named in the
+// path, such as the user to follow or block. It writes the error response and
+// returns false on failure.
+func (cfg *apiConfig) userTarget(w http.ResponseWriter, r *http.Request) (caller pgtype.UUID, target database.User, ok bool) {
+	caller, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return caller, target, false
+	}
+
+	target, err = cfg.lookupUser(context.Background(), r.PathValue("user"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return caller, target, false
+		}
+		log.Printf("Error getting user from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return caller, target, false
+	}
+
+	return caller, target, true
+}
+
+func (cfg *apiConfig) handleFollowUser(w http.ResponseWriter, r *http.Request) {
+	follower, followee, ok := cfg.userTarget(w, r)
+	if !ok {
+		return
+	}
+
+	if follower == followee.ID {
+		http.Error(w, "you cannot follow yourself", http.StatusBadRequest)
+		return
+	}
+
+	blocked, err := cfg.db.IsBlocked(context.Background(), database.IsBlockedParams{UserID: follower, OtherID: followee.ID})
+	if err != nil {
+		log.Printf("Error checking blocks: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if blocked {
+		http.Error(w, "you cannot follow this user", http.StatusForbidden)
+		return
+	}
+
+	// Following someone twice is not an error: the edge already exists.
+	followed, err := cfg.db.CreateFollow(context.Background(), database.CreateFollowParams{
+		FollowerID: follower,
+		FolloweeID: followee.ID,
+	})
+	if err != nil {
+		log.Printf("Error creating follow: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if followed > 0 {
+		cfg.notify(context.Background(), follower, notificationFollow, pgtype.UUID{}, followee.ID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	follower, followee, ok := cfg.userTarget(w, r)
+	if !ok {
+		return
+	}
+
+	unfollowed, err := cfg.db.DeleteFollow(context.Background(), database.DeleteFollowParams{
+		FollowerID: follower,
+		FolloweeID: followee.ID,
+	})
+	if err != nil {
+		log.Printf("Error deleting follow: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if unfollowed > 0 {
+		cfg.retractNotification(context.Background(), followee.ID, follower, notificationFollow, pgtype.UUID{})
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userEdge is a user on the far side of a follow, block or mute, with the
+// time it was created, which is what such lists are paginated by.
+type userEdge struct {
+	User      database.User
+	CreatedAt pgtype.Timestamp
+}
+
+func userEdgeCursor(edge userEdge) pageCursor {
+	return newPageCursor(edge.CreatedAt, edge.User.ID)
+}
+
+func (cfg *apiConfig) handleGetFollowers(w http.ResponseWriter, r *http.Request) {
+	cfg.listFollows(w, r, func(ctx context.Context, params database.GetFollowersParams) ([]userEdge, error) {
+		rows, err := cfg.db.GetFollowers(ctx, params)
+		var edges []userEdge
+		for _, row := range rows {
+			edges = append(edges, userEdge{User: row.User, CreatedAt: row.FollowedAt})
+		}
+		return edges, err
+	})
+}
+
+func (cfg *apiConfig) handleGetFollowing(w http.ResponseWriter, r *http.Request) {
+	cfg.listFollows(w, r, func(ctx context.Context, params database.GetFollowersParams) ([]userEdge, error) {
+		rows, err := cfg.db.GetFollowing(ctx, database.GetFollowingParams(params))
+		var edges []userEdge
+		for _, row := range rows {
+			edges = append(edges, userEdge{User: row.User, CreatedAt: row.FollowedAt})
+		}
+		return edges, err
+	})
+}
+
+// listFollows writes a page of the public profiles returned by query for the
+// user named in the path, most recently followed first. Users hidden from the
+// viewer are left out.
+func (cfg *apiConfig) listFollows(w http.ResponseWriter, r *http.Request, query func(context.Context, database.GetFollowersParams) ([]userEdge, error)) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, err := cfg.lookupUser(context.Background(), r.PathValue("user"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting user from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "follow lists are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	edges, err := query(context.Background(), database.GetFollowersParams{
+		UserID:          user.ID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		ViewerID:        viewer,
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting follows from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	edges, next := trimPage(edges, page, userEdgeCursor)
+
+	profiles := []publicProfile{}
+	for _, edge := range edges {
+		profiles = append(profiles, newPublicProfile(edge.User))
+	}
+
+	resp, err := json.Marshal(profiles)
+	if err != nil {
+		log.Printf("Error marshalling profiles: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
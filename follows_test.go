@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (ts *testServer) follow(follower, followee testUser) {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/users/"+followee.ID+"/follow", follower.bearer(), nil)
+	require.Equal(ts.t, http.StatusNoContent, rec.Code, rec.Body.String())
+}
+
+func TestFollow(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users/"+bob.ID+"/follow", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users/"+alice.ID+"/follow", alice.bearer(), nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Unknown User", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users/nobody/follow", alice.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Follow Twice", func(t *testing.T) {
+		for range 2 {
+			rec := ts.do(http.MethodPost, "/api/users/"+bob.ID+"/follow", alice.bearer(), nil)
+			require.Equal(t, http.StatusNoContent, rec.Code)
+		}
+
+		rec := ts.do(http.MethodGet, "/api/users/"+bob.ID, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		profile := decode[map[string]any](t, rec)
+		assert.EqualValues(t, 1, profile["followers_count"])
+		assert.EqualValues(t, 0, profile["following_count"])
+
+		rec = ts.do(http.MethodGet, "/api/users/"+alice.ID+"/following", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		following := decode[[]map[string]any](t, rec)
+		require.Len(t, following, 1)
+		assert.Equal(t, bob.ID, following[0]["id"])
+		assert.NotContains(t, following[0], "email")
+	})
+
+	t.Run("Unfollow", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, "/api/users/"+bob.ID+"/follow", alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/users/"+bob.ID+"/followers", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, decode[[]map[string]any](t, rec))
+	})
+}
+
+func TestGetFollowersPagination(t *testing.T) {
+	ts := newTestServer(t)
+	star := ts.signup("star@example.com")
+
+	var fans []testUser
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		fan := ts.signup(email)
+		ts.follow(fan, star)
+		fans = append(fans, fan)
+	}
+
+	rec := ts.do(http.MethodGet, "/api/users/"+star.ID+"/followers?limit=2", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	page := decode[[]map[string]any](t, rec)
+	require.Len(t, page, 2)
+	assert.Equal(t, fans[2].ID, page[0]["id"])
+	assert.Equal(t, fans[1].ID, page[1]["id"])
+
+	cursor := rec.Header().Get("X-Next-Cursor")
+	require.NotEmpty(t, cursor)
+
+	rec = ts.do(http.MethodGet, "/api/users/"+star.ID+"/followers?limit=2&cursor="+cursor, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	page = decode[[]map[string]any](t, rec)
+	require.Len(t, page, 1)
+	assert.Equal(t, fans[0].ID, page[0]["id"])
+	assert.Empty(t, rec.Header().Get("X-Next-Cursor"))
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chtozamm/chirpy/internal/database"
+)
+
+const (
+	maxHashtagLength   = 100
+	defaultTagsResults = 10
+	maxTagsResults     = 50
+)
+
+// hashtagPattern matches a #tag that is not glued to a preceding word or
+// URL. A tag is made of letters, digits and underscores and contains at least
+// one letter, so "#1" is not a tag.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_#&/])#([\p{L}\p{N}_]*\p{L}[\p{L}\p{N}_]*)`)
+
+var tagNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_]*$`)
+
+// extractHashtags returns the distinct, lowercased hashtags in body in the
+// order they first appear.
+func extractHashtags(body string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+
+	for _, match := range hashtagPattern.FindAllStringSubmatch(body, -1) {
+		tag := strings.ToLower(match[1])
+		if seen[tag] || utf8.RuneCountInString(tag) > maxHashtagLength {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// normalizeHashtag turns user input such as "#Go" into the stored form "go".
+func normalizeHashtag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	return tag, tagNamePattern.MatchString(tag) && utf8.RuneCountInString(tag) <= maxHashtagLength
+}
+
+// indexHashtags links a chirp to the hashtags in its body.
+func indexHashtags(ctx context.Context, db database.Querier, chirp database.Chirp) error {
+	tags := extractHashtags(chirp.Body)
+	if len(tags) == 0 {
+		return nil
+	}
+
+	return db.CreateChirpTags(ctx, database.CreateChirpTagsParams{
+		Names:     tags,
+		ChirpID:   chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+	})
+}
+
+// backfillHashtags indexes the hashtags of every existing chirp. It is safe
+// to run more than once.
+func backfillHashtags(ctx context.Context, db database.Querier) error {
+	const batchSize = 500
+
+	cursor := firstCursor
+	indexed := 0
+	for {
+		chirps, err := db.GetChirps(ctx, database.GetChirpsParams{
+			CursorCreatedAt: cursor.Timestamp(),
+			CursorID:        cursor.UUID(),
+			PageSize:        batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, chirp := range chirps {
+			err = indexHashtags(ctx, db, chirp)
+			if err != nil {
+				return err
+			}
+		}
+
+		indexed += len(chirps)
+		if len(chirps) < batchSize {
+			log.Printf("Indexed hashtags of %d chirps\n", indexed)
+			return nil
+		}
+		cursor = chirpCursor(chirps[len(chirps)-1])
+	}
+}
+
+func (cfg *apiConfig) handleGetHashtagChirps(w http.ResponseWriter, r *http.Request) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	tag, ok := normalizeHashtag(r.PathValue("tag"))
+	if !ok || tag == "" {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "hashtag timelines are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	chirps, err := cfg.db.GetChirpsByTag(context.Background(), database.GetChirpsByTagParams{
+		Name:            tag,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		ViewerID:        viewer,
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting chirps by tag from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	chirps, next := trimPage(chirps, page, chirpCursor)
+
+	responses, err := cfg.chirpResponses(context.Background(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses)
+	if err != nil {
+		log.Printf("Error marshalling chirps struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleGetHashtags(w http.ResponseWriter, r *http.Request) {
+	prefix, ok := normalizeHashtag(r.URL.Query().Get("prefix"))
+	if !ok || prefix == "" {
+		http.Error(w, "prefix must be a non-empty hashtag prefix", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultTagsResults
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxTagsResults)
+	}
+
+	rows, err := cfg.db.GetTagsByPrefix(context.Background(), database.GetTagsByPrefixParams{
+		// Underscore is the only LIKE wildcard a tag can contain.
+		Prefix:     strings.ReplaceAll(prefix, "_", `\_`),
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		log.Printf("Error getting tags from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	type tag struct {
+		Tag        string `json:"tag"`
+		ChirpCount int64  `json:"chirp_count"`
+	}
+
+	tags := []tag{}
+	for _, row := range rows {
+		tags = append(tags, tag{Tag: row.Name, ChirpCount: row.ChirpCount})
+	}
+
+	resp, err := json.Marshal(tags)
+	if err != nil {
+		log.Printf("Error marshalling tags: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
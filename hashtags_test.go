@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"None", "no tags here", nil},
+		{"Normalized And Deduplicated", "#Go is #go and #GO", []string{"go"}},
+		{"Order Of Appearance", "#b then #a", []string{"b", "a"}},
+		{"Trailing Punctuation", "love #chirpy!", []string{"chirpy"}},
+		{"Unicode", "#café time", []string{"café"}},
+		{"Underscore", "#go_lang", []string{"go_lang"}},
+		{"Numbers Only", "#1 fan", nil},
+		{"Glued To Word", "issue#12 and foo#bar", nil},
+		{"Anchors", "https://example.com/#section", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractHashtags(tt.body))
+		})
+	}
+}
+
+func TestHashtags(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+
+	first := ts.createChirp(user, "Learning #golang")
+	second := ts.createChirp(user, "#GoLang and #gophers")
+	ts.createChirp(user, "#go")
+
+	t.Run("Timeline", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/hashtags/golang/chirps", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		chirps := decode[[]testChirp](t, rec)
+		require.Len(t, chirps, 2)
+		assert.Equal(t, second.ID, chirps[0].ID)
+		assert.Equal(t, first.ID, chirps[1].ID)
+	})
+
+	t.Run("Timeline Pagination", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/hashtags/%23GoLang/chirps?limit=1", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, decode[[]testChirp](t, rec), 1)
+
+		rec = ts.do(http.MethodGet, "/api/hashtags/golang/chirps?limit=1&cursor="+rec.Header().Get("X-Next-Cursor"), "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		chirps := decode[[]testChirp](t, rec)
+		require.Len(t, chirps, 1)
+		assert.Equal(t, first.ID, chirps[0].ID)
+		assert.Empty(t, rec.Header().Get("Link"))
+	})
+
+	t.Run("Autocomplete", func(t *testing.T) {
+		type tag struct {
+			Tag        string `json:"tag"`
+			ChirpCount int64  `json:"chirp_count"`
+		}
+
+		rec := ts.do(http.MethodGet, "/api/hashtags?prefix=go", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []tag{{"golang", 2}, {"go", 1}, {"gophers", 1}}, decode[[]tag](t, rec))
+	})
+
+	t.Run("Deleted Chirp Is Unlinked", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, "/api/chirps/"+second.ID, user.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/hashtags?prefix=gopher", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "[]", rec.Body.String())
+
+		rec = ts.do(http.MethodGet, "/api/hashtags/golang/chirps", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, decode[[]testChirp](t, rec), 1)
+	})
+}
+
+func TestBackfillHashtags(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+	ctx := context.Background()
+
+	// Insert chirps directly so they bypass the indexing done by the handler.
+	for _, body := range []string{"#backfill one", "#backfill two", "nothing"} {
+		_, err := ts.store.CreateChirp(ctx, database.CreateChirpParams{Body: body, UserID: ts.userID(user)})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, backfillHashtags(ctx, ts.store))
+	require.NoError(t, backfillHashtags(ctx, ts.store))
+
+	rec := ts.do(http.MethodGet, "/api/hashtags/backfill/chirps", "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, decode[[]testChirp](t, rec), 2)
+}
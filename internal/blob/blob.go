@@ -0,0 +1,113 @@
+// Package blob stores opaque files, such as uploaded media, by key.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrNotFound is returned by Store.Get when no blob has the key.
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs by key. Keys are made of letters, digits, '.', '_' and
+// '-'. Blobs are immutable: a key is written once and later only deleted.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Put stores the contents of r under key.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get opens the blob stored under key, or returns ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key. Deleting a missing blob is
+	// not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]*$`)
+
+// ValidKey reports whether key can be used with a Store.
+func ValidKey(key string) bool {
+	return validKey.MatchString(key)
+}
+
+// Local is a Store keeping each blob in a file of a directory on disk.
+type Local struct {
+	dir string
+}
+
+var _ Store = (*Local)(nil)
+
+// NewLocal returns a Local storing blobs in dir, creating it if needed.
+func NewLocal(dir string) (*Local, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &Local{dir: dir}, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", errors.New("invalid blob key")
+	}
+	return filepath.Join(l.dir, key), nil
+}
+
+// Put writes the blob to a temporary file first and renames it into place,
+// so readers never see a partially written blob.
+func (l *Local) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(l.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get returns an *os.File, which also implements io.Seeker.
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
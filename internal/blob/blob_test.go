@@ -0,0 +1,58 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewLocal(dir)
+	require.NoError(t, err)
+
+	t.Run("Put And Get", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "a.jpg", strings.NewReader("data")))
+
+		r, err := store.Get(ctx, "a.jpg")
+		require.NoError(t, err)
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "data", string(data))
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "temporary files must be cleaned up")
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		_, err := store.Get(ctx, "missing.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, store.Delete(ctx, "a.jpg"))
+		require.NoError(t, store.Delete(ctx, "a.jpg"))
+
+		_, err := store.Get(ctx, "a.jpg")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Invalid Keys", func(t *testing.T) {
+		outside := filepath.Join(filepath.Dir(dir), "outside")
+		for _, key := range []string{"", "../outside", "a/b", ".hidden", filepath.Base(outside) + "/.."} {
+			assert.Error(t, store.Put(ctx, key, strings.NewReader("x")), key)
+			_, err := store.Get(ctx, key)
+			assert.ErrorIs(t, err, ErrNotFound, key)
+		}
+		assert.NoFileExists(t, outside)
+	})
+}
@@ -12,24 +12,42 @@ import (
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at)
 VALUES (
 	gen_random_uuid(),
 	NOW(),
 	NOW(),
 	$1,
-	$2
+	$2,
+	$3,
+	$4,
+	$5,
+	$6::uuid[],
+	$7
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at
 `
 
 type CreateChirpParams struct {
-	Body   string      `json:"body"`
-	UserID pgtype.UUID `json:"user_id"`
+	Body           string           `json:"body"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	QuoteOf        pgtype.UUID      `json:"quote_of"`
+	InReplyTo      pgtype.UUID      `json:"in_reply_to"`
+	ConversationID pgtype.UUID      `json:"conversation_id"`
+	AncestorIds    []pgtype.UUID    `json:"ancestor_ids"`
+	ExpiresAt      pgtype.Timestamp `json:"expires_at"`
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRow(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRow(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.QuoteOf,
+		arg.InReplyTo,
+		arg.ConversationID,
+		arg.AncestorIds,
+		arg.ExpiresAt,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,6 +55,46 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.InReplyTo,
+		&i.ConversationID,
+		&i.AncestorIds,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of)
+VALUES (gen_random_uuid(), NOW(), NOW(), '', $1, $2)
+ON CONFLICT (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL DO NOTHING
+RETURNING id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at
+`
+
+type CreateRechirpParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	RechirpOf pgtype.UUID `json:"rechirp_of"`
+}
+
+// Returns no rows if the user has already rechirped the chirp.
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRow(ctx, createRechirp, arg.UserID, arg.RechirpOf)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.InReplyTo,
+		&i.ConversationID,
+		&i.AncestorIds,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
@@ -50,8 +108,46 @@ func (q *Queries) DeleteChirp(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const deleteExpiredChirps = `-- name: DeleteExpiredChirps :execrows
+DELETE FROM chirps WHERE id IN (
+	SELECT id FROM chirps
+	WHERE expires_at <= NOW()
+	ORDER BY expires_at
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED
+)
+`
+
+// Deletes up to max_results expired chirps. Their likes, bookmarks, hashtag
+// links, polls and rechirps go with them through ON DELETE CASCADE, and their
+// media is left to the media garbage collector.
+func (q *Queries) DeleteExpiredChirps(ctx context.Context, maxResults int32) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredChirps, maxResults)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteRechirp = `-- name: DeleteRechirp :execrows
+DELETE FROM chirps WHERE user_id = $1 AND rechirp_of = $2
+`
+
+type DeleteRechirpParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	RechirpOf pgtype.UUID `json:"rechirp_of"`
+}
+
+func (q *Queries) DeleteRechirp(ctx context.Context, arg DeleteRechirpParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteRechirp, arg.UserID, arg.RechirpOf)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps WHERE id = $1
+SELECT id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at FROM chirps WHERE id = $1 AND (expires_at IS NULL OR expires_at > NOW())
 `
 
 func (q *Queries) GetChirp(ctx context.Context, id pgtype.UUID) (Chirp, error) {
@@ -63,16 +159,131 @@ func (q *Queries) GetChirp(ctx context.Context, id pgtype.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.SearchVector,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.InReplyTo,
+		&i.ConversationID,
+		&i.AncestorIds,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
 const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps ORDER BY created_at ASC
+SELECT id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at FROM chirps
+WHERE (created_at, id) > ($1::timestamp, $2::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users($3::uuid))
+	AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type GetChirpsParams struct {
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.UUID      `json:"cursor_id"`
+	ViewerID        pgtype.UUID      `json:"viewer_id"`
+	PageSize        int32            `json:"page_size"`
+}
+
+func (q *Queries) GetChirps(ctx context.Context, arg GetChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.Query(ctx, getChirps,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.InReplyTo,
+			&i.ConversationID,
+			&i.AncestorIds,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at FROM chirps WHERE id = ANY($1::uuid[]) AND (expires_at IS NULL OR expires_at > NOW())
+`
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, ids []pgtype.UUID) ([]Chirp, error) {
+	rows, err := q.db.Query(ctx, getChirpsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.InReplyTo,
+			&i.ConversationID,
+			&i.AncestorIds,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsDesc = `-- name: GetChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at FROM chirps
+WHERE (created_at, id) < ($1::timestamp, $2::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users($3::uuid))
+	AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at DESC, id DESC
+LIMIT $4
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.Query(ctx, getChirps)
+type GetChirpsDescParams struct {
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.UUID      `json:"cursor_id"`
+	ViewerID        pgtype.UUID      `json:"viewer_id"`
+	PageSize        int32            `json:"page_size"`
+}
+
+func (q *Queries) GetChirpsDesc(ctx context.Context, arg GetChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.Query(ctx, getChirpsDesc,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +297,13 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.InReplyTo,
+			&i.ConversationID,
+			&i.AncestorIds,
+			&i.ExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -98,11 +316,90 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 }
 
 const getChirpsFromAuthor = `-- name: GetChirpsFromAuthor :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps WHERE user_id = $1 ORDER BY created_at ASC
+SELECT id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at FROM chirps
+WHERE user_id = $1
+	AND (created_at, id) > ($2::timestamp, $3::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users($4::uuid))
+	AND id IS DISTINCT FROM (SELECT pinned_chirp_id FROM users WHERE users.id = $1)
+	AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at ASC, id ASC
+LIMIT $5
+`
+
+type GetChirpsFromAuthorParams struct {
+	UserID          pgtype.UUID      `json:"user_id"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.UUID      `json:"cursor_id"`
+	ViewerID        pgtype.UUID      `json:"viewer_id"`
+	PageSize        int32            `json:"page_size"`
+}
+
+func (q *Queries) GetChirpsFromAuthor(ctx context.Context, arg GetChirpsFromAuthorParams) ([]Chirp, error) {
+	rows, err := q.db.Query(ctx, getChirpsFromAuthor,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.InReplyTo,
+			&i.ConversationID,
+			&i.AncestorIds,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsFromAuthorDesc = `-- name: GetChirpsFromAuthorDesc :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at FROM chirps
+WHERE user_id = $1
+	AND (created_at, id) < ($2::timestamp, $3::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users($4::uuid))
+	AND id IS DISTINCT FROM (SELECT pinned_chirp_id FROM users WHERE users.id = $1)
+	AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at DESC, id DESC
+LIMIT $5
 `
 
-func (q *Queries) GetChirpsFromAuthor(ctx context.Context, userID pgtype.UUID) ([]Chirp, error) {
-	rows, err := q.db.Query(ctx, getChirpsFromAuthor, userID)
+type GetChirpsFromAuthorDescParams struct {
+	UserID          pgtype.UUID      `json:"user_id"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.UUID      `json:"cursor_id"`
+	ViewerID        pgtype.UUID      `json:"viewer_id"`
+	PageSize        int32            `json:"page_size"`
+}
+
+func (q *Queries) GetChirpsFromAuthorDesc(ctx context.Context, arg GetChirpsFromAuthorDescParams) ([]Chirp, error) {
+	rows, err := q.db.Query(ctx, getChirpsFromAuthorDesc,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -116,6 +413,267 @@ func (q *Queries) GetChirpsFromAuthor(ctx context.Context, userID pgtype.UUID) (
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.SearchVector,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.InReplyTo,
+			&i.ConversationID,
+			&i.AncestorIds,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRechirp = `-- name: GetRechirp :one
+SELECT id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at FROM chirps WHERE user_id = $1 AND rechirp_of = $2
+`
+
+type GetRechirpParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	RechirpOf pgtype.UUID `json:"rechirp_of"`
+}
+
+func (q *Queries) GetRechirp(ctx context.Context, arg GetRechirpParams) (Chirp, error) {
+	row := q.db.QueryRow(ctx, getRechirp, arg.UserID, arg.RechirpOf)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.InReplyTo,
+		&i.ConversationID,
+		&i.AncestorIds,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getRechirpCounts = `-- name: GetRechirpCounts :many
+SELECT rechirp_of::uuid AS chirp_id, COUNT(*) AS rechirp_count FROM chirps
+WHERE rechirp_of = ANY($1::uuid[])
+GROUP BY rechirp_of
+`
+
+type GetRechirpCountsRow struct {
+	ChirpID      pgtype.UUID `json:"chirp_id"`
+	RechirpCount int64       `json:"rechirp_count"`
+}
+
+func (q *Queries) GetRechirpCounts(ctx context.Context, chirpIds []pgtype.UUID) ([]GetRechirpCountsRow, error) {
+	rows, err := q.db.Query(ctx, getRechirpCounts, chirpIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRechirpCountsRow
+	for rows.Next() {
+		var i GetRechirpCountsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.RechirpCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRechirpedChirpIDs = `-- name: GetRechirpedChirpIDs :many
+SELECT rechirp_of::uuid AS chirp_id FROM chirps
+WHERE user_id = $1 AND rechirp_of = ANY($2::uuid[])
+`
+
+type GetRechirpedChirpIDsParams struct {
+	UserID   pgtype.UUID   `json:"user_id"`
+	ChirpIds []pgtype.UUID `json:"chirp_ids"`
+}
+
+func (q *Queries) GetRechirpedChirpIDs(ctx context.Context, arg GetRechirpedChirpIDsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getRechirpedChirpIDs, arg.UserID, arg.ChirpIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var chirp_id pgtype.UUID
+		if err := rows.Scan(&chirp_id); err != nil {
+			return nil, err
+		}
+		items = append(items, chirp_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReplyCounts = `-- name: GetReplyCounts :many
+SELECT in_reply_to::uuid AS chirp_id, COUNT(*) AS reply_count FROM chirps
+WHERE in_reply_to = ANY($1::uuid[])
+	AND (expires_at IS NULL OR expires_at > NOW())
+GROUP BY in_reply_to
+`
+
+type GetReplyCountsRow struct {
+	ChirpID    pgtype.UUID `json:"chirp_id"`
+	ReplyCount int64       `json:"reply_count"`
+}
+
+func (q *Queries) GetReplyCounts(ctx context.Context, chirpIds []pgtype.UUID) ([]GetReplyCountsRow, error) {
+	rows, err := q.db.Query(ctx, getReplyCounts, chirpIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReplyCountsRow
+	for rows.Next() {
+		var i GetReplyCountsRow
+		if err := rows.Scan(
+			&i.ChirpID,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadDescendants = `-- name: GetThreadDescendants :many
+SELECT id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at FROM chirps
+WHERE ancestor_ids @> ARRAY[$1::uuid]
+	AND (created_at, id) > ($2::timestamp, $3::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users($4::uuid))
+	AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at ASC, id ASC
+LIMIT $5
+`
+
+type GetThreadDescendantsParams struct {
+	ChirpID         pgtype.UUID      `json:"chirp_id"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.UUID      `json:"cursor_id"`
+	ViewerID        pgtype.UUID      `json:"viewer_id"`
+	PageSize        int32            `json:"page_size"`
+}
+
+func (q *Queries) GetThreadDescendants(ctx context.Context, arg GetThreadDescendantsParams) ([]Chirp, error) {
+	rows, err := q.db.Query(ctx, getThreadDescendants,
+		arg.ChirpID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.InReplyTo,
+			&i.ConversationID,
+			&i.AncestorIds,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimeline = `-- name: GetTimeline :many
+WITH authors AS (
+	SELECT $1::uuid AS id
+	UNION ALL
+	SELECT followee_id FROM follows
+	WHERE follower_id = $1
+		AND followee_id NOT IN (SELECT user_id FROM hidden_users($1))
+)
+SELECT timeline.id, timeline.created_at, timeline.updated_at, timeline.body, timeline.user_id, timeline.search_vector, timeline.rechirp_of, timeline.quote_of, timeline.in_reply_to, timeline.conversation_id, timeline.ancestor_ids, timeline.expires_at FROM authors
+CROSS JOIN LATERAL (
+	SELECT id, created_at, updated_at, body, user_id, search_vector, rechirp_of, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at FROM chirps
+	WHERE chirps.user_id = authors.id
+		AND (chirps.created_at, chirps.id) < ($2::timestamp, $3::uuid)
+		AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+	ORDER BY chirps.created_at DESC, chirps.id DESC
+	LIMIT $4
+) AS timeline
+ORDER BY timeline.created_at DESC, timeline.id DESC
+LIMIT $4
+`
+
+type GetTimelineParams struct {
+	UserID          pgtype.UUID      `json:"user_id"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.UUID      `json:"cursor_id"`
+	PageSize        int32            `json:"page_size"`
+}
+
+// Each author's newest chirps are read from chirps_user_id_created_at_idx
+// separately, so the cost grows with the number of followed accounts times the
+// page size instead of with their total number of chirps.
+func (q *Queries) GetTimeline(ctx context.Context, arg GetTimelineParams) ([]Chirp, error) {
+	rows, err := q.db.Query(ctx, getTimeline,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.SearchVector,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.InReplyTo,
+			&i.ConversationID,
+			&i.AncestorIds,
+			&i.ExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -135,3 +693,160 @@ func (q *Queries) RemoveAllChirps(ctx context.Context) error {
 	_, err := q.db.Exec(ctx, removeAllChirps)
 	return err
 }
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.rechirp_of, chirps.quote_of, chirps.in_reply_to, chirps.conversation_id, chirps.ancestor_ids, chirps.expires_at,
+	ts_rank(chirps.search_vector, query)::real AS rank,
+	ts_headline('english', html_escape(chirps.body), query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2')::text AS snippet
+FROM chirps, to_tsquery('english', $1) query
+WHERE chirps.search_vector @@ query
+	AND ($2::uuid IS NULL OR chirps.user_id = $2)
+	AND ($3::timestamp IS NULL OR chirps.created_at >= $3)
+	AND ($4::timestamp IS NULL OR chirps.created_at < $4)
+	AND (chirps.created_at, chirps.id) < ($5::timestamp, $6::uuid)
+	AND chirps.user_id NOT IN (SELECT user_id FROM hidden_users($7::uuid))
+	AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $8
+`
+
+type SearchChirpsParams struct {
+	Query           string           `json:"query"`
+	AuthorID        pgtype.UUID      `json:"author_id"`
+	Since           pgtype.Timestamp `json:"since"`
+	Until           pgtype.Timestamp `json:"until"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.UUID      `json:"cursor_id"`
+	ViewerID        pgtype.UUID      `json:"viewer_id"`
+	PageSize        int32            `json:"page_size"`
+}
+
+type SearchChirpsRow struct {
+	Chirp   Chirp   `json:"chirp"`
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.Query(ctx, searchChirps,
+		arg.Query,
+		arg.AuthorID,
+		arg.Since,
+		arg.Until,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.RechirpOf,
+			&i.Chirp.QuoteOf,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.ConversationID,
+			&i.Chirp.AncestorIds,
+			&i.Chirp.ExpiresAt,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchChirpsByRank = `-- name: SearchChirpsByRank :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.rechirp_of, chirps.quote_of, chirps.in_reply_to, chirps.conversation_id, chirps.ancestor_ids, chirps.expires_at,
+	ts_rank(chirps.search_vector, query)::real AS rank,
+	ts_headline('english', html_escape(chirps.body), query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2')::text AS snippet
+FROM chirps, to_tsquery('english', $1) query
+WHERE chirps.search_vector @@ query
+	AND ($2::uuid IS NULL OR chirps.user_id = $2)
+	AND ($3::timestamp IS NULL OR chirps.created_at >= $3)
+	AND ($4::timestamp IS NULL OR chirps.created_at < $4)
+	AND (ts_rank(chirps.search_vector, query)::real, chirps.created_at, chirps.id)
+		< ($5::real, $6::timestamp, $7::uuid)
+	AND chirps.user_id NOT IN (SELECT user_id FROM hidden_users($8::uuid))
+	AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT $9
+`
+
+type SearchChirpsByRankParams struct {
+	Query           string           `json:"query"`
+	AuthorID        pgtype.UUID      `json:"author_id"`
+	Since           pgtype.Timestamp `json:"since"`
+	Until           pgtype.Timestamp `json:"until"`
+	CursorRank      float32          `json:"cursor_rank"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.UUID      `json:"cursor_id"`
+	ViewerID        pgtype.UUID      `json:"viewer_id"`
+	PageSize        int32            `json:"page_size"`
+}
+
+type SearchChirpsByRankRow struct {
+	Chirp   Chirp   `json:"chirp"`
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+func (q *Queries) SearchChirpsByRank(ctx context.Context, arg SearchChirpsByRankParams) ([]SearchChirpsByRankRow, error) {
+	rows, err := q.db.Query(ctx, searchChirpsByRank,
+		arg.Query,
+		arg.AuthorID,
+		arg.Since,
+		arg.Until,
+		arg.CursorRank,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsByRankRow
+	for rows.Next() {
+		var i SearchChirpsByRankRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.SearchVector,
+			&i.Chirp.RechirpOf,
+			&i.Chirp.QuoteOf,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.ConversationID,
+			&i.Chirp.AncestorIds,
+			&i.Chirp.ExpiresAt,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -8,12 +8,151 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Block struct {
+	BlockerID pgtype.UUID      `json:"blocker_id"`
+	BlockedID pgtype.UUID      `json:"blocked_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Bookmark struct {
+	UserID    pgtype.UUID      `json:"user_id"`
+	ChirpID   pgtype.UUID      `json:"chirp_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
 type Chirp struct {
+	ID             pgtype.UUID      `json:"id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	Body           string           `json:"body"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	SearchVector   interface{}      `json:"-"`
+	RechirpOf      pgtype.UUID      `json:"rechirp_of"`
+	QuoteOf        pgtype.UUID      `json:"quote_of"`
+	InReplyTo      pgtype.UUID      `json:"in_reply_to"`
+	ConversationID pgtype.UUID      `json:"conversation_id"`
+	AncestorIds    []pgtype.UUID    `json:"-"`
+	ExpiresAt      pgtype.Timestamp `json:"expires_at"`
+}
+
+type ChirpTag struct {
+	ChirpID   pgtype.UUID      `json:"chirp_id"`
+	TagID     pgtype.UUID      `json:"tag_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Conversation struct {
+	ID        pgtype.UUID      `json:"id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	DirectKey pgtype.Text      `json:"direct_key"`
+}
+
+type ConversationParticipant struct {
+	ConversationID pgtype.UUID      `json:"conversation_id"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	LastReadAt     pgtype.Timestamp `json:"last_read_at"`
+}
+
+type DisabledNotification struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Type   string      `json:"type"`
+}
+
+type Draft struct {
 	ID        pgtype.UUID      `json:"id"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	UserID    pgtype.UUID      `json:"user_id"`
 	Body      string           `json:"body"`
+	QuoteOf   pgtype.UUID      `json:"quote_of"`
+	InReplyTo pgtype.UUID      `json:"in_reply_to"`
+}
+
+type FileserverHit struct {
+	ID   bool  `json:"id"`
+	Hits int64 `json:"hits"`
+}
+
+type Follow struct {
+	FollowerID pgtype.UUID      `json:"follower_id"`
+	FolloweeID pgtype.UUID      `json:"followee_id"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type Like struct {
+	UserID    pgtype.UUID      `json:"user_id"`
+	ChirpID   pgtype.UUID      `json:"chirp_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type List struct {
+	ID          pgtype.UUID      `json:"id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	UserID      pgtype.UUID      `json:"user_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	IsPrivate   bool             `json:"is_private"`
+}
+
+type ListMember struct {
+	ListID    pgtype.UUID      `json:"list_id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Medium struct {
+	ID           pgtype.UUID      `json:"id"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UserID       pgtype.UUID      `json:"user_id"`
+	ChirpID      pgtype.UUID      `json:"chirp_id"`
+	AttachedAt   pgtype.Timestamp `json:"attached_at"`
+	Position     int32            `json:"position"`
+	AltText      string           `json:"alt_text"`
+	ContentType  string           `json:"content_type"`
+	Width        int32            `json:"width"`
+	Height       int32            `json:"height"`
+	BlobKey      string           `json:"blob_key"`
+	ThumbnailKey string           `json:"thumbnail_key"`
+}
+
+type Message struct {
+	ID             pgtype.UUID      `json:"id"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	ConversationID pgtype.UUID      `json:"conversation_id"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	Body           string           `json:"body"`
+}
+
+type Mute struct {
+	MuterID   pgtype.UUID      `json:"muter_id"`
+	MutedID   pgtype.UUID      `json:"muted_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Notification struct {
+	ID        pgtype.UUID      `json:"id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
 	UserID    pgtype.UUID      `json:"user_id"`
+	ActorID   pgtype.UUID      `json:"actor_id"`
+	Type      string           `json:"type"`
+	ChirpID   pgtype.UUID      `json:"chirp_id"`
+	ReadAt    pgtype.Timestamp `json:"read_at"`
+}
+
+type Poll struct {
+	ChirpID  pgtype.UUID      `json:"chirp_id"`
+	Options  []string         `json:"options"`
+	ClosesAt pgtype.Timestamp `json:"closes_at"`
+}
+
+type PollVote struct {
+	ChirpID     pgtype.UUID      `json:"chirp_id"`
+	UserID      pgtype.UUID      `json:"user_id"`
+	OptionIndex int32            `json:"option_index"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
 }
 
 type RefreshToken struct {
@@ -25,6 +164,21 @@ type RefreshToken struct {
 	RevokedAt pgtype.Timestamp `json:"revoked_at"`
 }
 
+type ScheduledChirp struct {
+	ID        pgtype.UUID      `json:"id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	Body      string           `json:"body"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	PublishAt pgtype.Timestamp `json:"publish_at"`
+}
+
+type Tag struct {
+	ID        pgtype.UUID      `json:"id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	Name      string           `json:"name"`
+}
+
 type User struct {
 	ID             pgtype.UUID      `json:"id"`
 	CreatedAt      pgtype.Timestamp `json:"created_at"`
@@ -32,4 +186,9 @@ type User struct {
 	Email          string           `json:"email"`
 	HashedPassword string           `json:"hashed_password"`
 	IsChirpyRed    bool             `json:"is_chirpy_red"`
+	Handle         pgtype.Text      `json:"handle"`
+	DisplayName    string           `json:"display_name"`
+	Bio            string           `json:"bio"`
+	AvatarUrl      string           `json:"avatar_url"`
+	PinnedChirpID  pgtype.UUID      `json:"pinned_chirp_id"`
 }
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolConfig describes how to open and supervise a connection pool. Zero
+// values fall back to the pgxpool defaults.
+type PoolConfig struct {
+	URL               string
+	MaxConns          int32
+	MinConns          int32
+	HealthCheckPeriod time.Duration
+	// ConnectTimeout bounds how long Connect keeps retrying before giving up.
+	ConnectTimeout time.Duration
+}
+
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+// Pool is a concurrency-safe pgxpool.Pool that can be passed to New. It
+// remembers whether the last health check reached the database.
+type Pool struct {
+	*pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// PoolStats is a JSON-friendly snapshot of pgxpool.Stat.
+type PoolStats struct {
+	Healthy                 bool   `json:"healthy"`
+	MaxConns                int32  `json:"max_conns"`
+	TotalConns              int32  `json:"total_conns"`
+	AcquiredConns           int32  `json:"acquired_conns"`
+	IdleConns               int32  `json:"idle_conns"`
+	ConstructingConns       int32  `json:"constructing_conns"`
+	AcquireCount            int64  `json:"acquire_count"`
+	AcquireDuration         string `json:"acquire_duration"`
+	EmptyAcquireCount       int64  `json:"empty_acquire_count"`
+	CanceledAcquireCount    int64  `json:"canceled_acquire_count"`
+	NewConnsCount           int64  `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64  `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64  `json:"max_idle_destroy_count"`
+}
+
+// Connect opens a pool and pings the database, retrying with exponential
+// backoff until it succeeds, cfg.ConnectTimeout elapses or ctx is done.
+func Connect(ctx context.Context, cfg PoolConfig) (*Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parse database url: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolConfig.MinConns = cfg.MinConns
+	}
+	if cfg.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	}
+
+	if cfg.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("create pool: %w", err)
+	}
+
+	p := &Pool{Pool: pool}
+	backoff := minBackoff
+	for {
+		err = pool.Ping(ctx)
+		if err == nil {
+			p.healthy.Store(true)
+			return p, nil
+		}
+
+		log.Printf("Database is not reachable, retrying in %v: %v\n", backoff, err)
+		select {
+		case <-ctx.Done():
+			pool.Close()
+			return nil, fmt.Errorf("connect to database: %w", err)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// Monitor pings the database every interval until ctx is done. When a ping
+// fails the pool is reset so that broken connections are not handed out, and
+// pings are retried with exponential backoff until the database is back.
+func (p *Pool) Monitor(ctx context.Context, interval time.Duration) {
+	wait := interval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := p.Ping(pingCtx)
+		cancel()
+
+		if err == nil {
+			if !p.healthy.Swap(true) {
+				log.Println("Database connection restored")
+			}
+			wait = interval
+			continue
+		}
+
+		if p.healthy.Swap(false) {
+			log.Printf("Lost database connection: %v\n", err)
+			p.Reset()
+			wait = minBackoff
+		} else {
+			wait = min(wait*2, maxBackoff)
+		}
+		log.Printf("Reconnecting to database in %v\n", wait)
+	}
+}
+
+// Healthy reports whether the most recent ping succeeded.
+func (p *Pool) Healthy() bool {
+	return p.healthy.Load()
+}
+
+// Stats returns the current pool statistics.
+func (p *Pool) Stats() PoolStats {
+	stat := p.Stat()
+	return PoolStats{
+		Healthy:                 p.Healthy(),
+		MaxConns:                stat.MaxConns(),
+		TotalConns:              stat.TotalConns(),
+		AcquiredConns:           stat.AcquiredConns(),
+		IdleConns:               stat.IdleConns(),
+		ConstructingConns:       stat.ConstructingConns(),
+		AcquireCount:            stat.AcquireCount(),
+		AcquireDuration:         stat.AcquireDuration().String(),
+		EmptyAcquireCount:       stat.EmptyAcquireCount(),
+		CanceledAcquireCount:    stat.CanceledAcquireCount(),
+		NewConnsCount:           stat.NewConnsCount(),
+		MaxLifetimeDestroyCount: stat.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     stat.MaxIdleDestroyCount(),
+	}
+}
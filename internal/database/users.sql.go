@@ -12,24 +12,26 @@ import (
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
 VALUES (
 	gen_random_uuid(),
 	NOW(),
 	NOW(),
 	$1,
-	$2
+	$2,
+	$3
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id
 `
 
 type CreateUserParams struct {
-	Email          string `json:"email"`
-	HashedPassword string `json:"hashed_password"`
+	Email          string      `json:"email"`
+	HashedPassword string      `json:"hashed_password"`
+	Handle         pgtype.Text `json:"handle"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRow(ctx, createUser, arg.Email, arg.HashedPassword, arg.Handle)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -38,12 +40,50 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+	)
+	return i, err
+}
+
+const getPinnedChirp = `-- name: GetPinnedChirp :one
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.search_vector, chirps.rechirp_of, chirps.quote_of, chirps.in_reply_to, chirps.conversation_id, chirps.ancestor_ids, chirps.expires_at FROM users
+JOIN chirps ON chirps.id = users.pinned_chirp_id
+WHERE users.id = $1
+	AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+	AND chirps.user_id NOT IN (SELECT user_id FROM hidden_users($2::uuid))
+`
+
+type GetPinnedChirpParams struct {
+	UserID   pgtype.UUID `json:"user_id"`
+	ViewerID pgtype.UUID `json:"viewer_id"`
+}
+
+func (q *Queries) GetPinnedChirp(ctx context.Context, arg GetPinnedChirpParams) (Chirp, error) {
+	row := q.db.QueryRow(ctx, getPinnedChirp, arg.UserID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.SearchVector,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.InReplyTo,
+		&i.ConversationID,
+		&i.AncestorIds,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users WHERE email = $1
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -56,12 +96,40 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
+	)
+	return i, err
+}
+
+const getUserByHandle = `-- name: GetUserByHandle :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id FROM users WHERE lower(handle) = lower($1)
+`
+
+func (q *Queries) GetUserByHandle(ctx context.Context, handle string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByHandle, handle)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users WHERE id = $1
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id FROM users WHERE id = $1
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (User, error) {
@@ -74,10 +142,115 @@ func (q *Queries) GetUserByID(ctx context.Context, id pgtype.UUID) (User, error)
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
 	)
 	return i, err
 }
 
+const getUsersByHandles = `-- name: GetUsersByHandles :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id FROM users WHERE lower(handle) = ANY($1::text[])
+`
+
+// handles must be lowercase.
+func (q *Queries) GetUsersByHandles(ctx context.Context, handles []string) ([]User, error) {
+	rows, err := q.db.Query(ctx, getUsersByHandles, handles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarUrl,
+			&i.PinnedChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id FROM users WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) GetUsersByIDs(ctx context.Context, ids []pgtype.UUID) ([]User, error) {
+	rows, err := q.db.Query(ctx, getUsersByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.Handle,
+			&i.DisplayName,
+			&i.Bio,
+			&i.AvatarUrl,
+			&i.PinnedChirpID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pinChirp = `-- name: PinChirp :execrows
+UPDATE users SET pinned_chirp_id = $1, updated_at = NOW()
+WHERE users.id = $2
+	AND EXISTS (
+		SELECT 1 FROM chirps
+		WHERE chirps.id = $1
+			AND chirps.user_id = $2
+			AND chirps.rechirp_of IS NULL
+			AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+	)
+`
+
+type PinChirpParams struct {
+	ChirpID pgtype.UUID `json:"chirp_id"`
+	UserID  pgtype.UUID `json:"user_id"`
+}
+
+// Pins a chirp to the profile of its author. Nothing is updated unless the
+// chirp is one of the user's own, visible chirps and not a rechirp.
+func (q *Queries) PinChirp(ctx context.Context, arg PinChirpParams) (int64, error) {
+	result, err := q.db.Exec(ctx, pinChirp, arg.ChirpID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const removeAllUsers = `-- name: RemoveAllUsers :exec
 DELETE FROM users
 `
@@ -87,13 +260,27 @@ func (q *Queries) RemoveAllUsers(ctx context.Context) error {
 	return err
 }
 
+const unpinChirp = `-- name: UnpinChirp :exec
+UPDATE users SET pinned_chirp_id = NULL, updated_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) UnpinChirp(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, unpinChirp, id)
+	return err
+}
+
 const updateUser = `-- name: UpdateUser :one
-UPDATE users SET email = $1, hashed_password = $2, updated_at = $3 WHERE id = $4 RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+UPDATE users SET email = $1, hashed_password = $2, handle = $3, display_name = $4, bio = $5, avatar_url = $6, updated_at = $7
+WHERE id = $8 RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, handle, display_name, bio, avatar_url, pinned_chirp_id
 `
 
 type UpdateUserParams struct {
 	Email          string           `json:"email"`
 	HashedPassword string           `json:"hashed_password"`
+	Handle         pgtype.Text      `json:"handle"`
+	DisplayName    string           `json:"display_name"`
+	Bio            string           `json:"bio"`
+	AvatarUrl      string           `json:"avatar_url"`
 	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
 	ID             pgtype.UUID      `json:"id"`
 }
@@ -102,6 +289,10 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 	row := q.db.QueryRow(ctx, updateUser,
 		arg.Email,
 		arg.HashedPassword,
+		arg.Handle,
+		arg.DisplayName,
+		arg.Bio,
+		arg.AvatarUrl,
 		arg.UpdatedAt,
 		arg.ID,
 	)
@@ -113,6 +304,11 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Handle,
+		&i.DisplayName,
+		&i.Bio,
+		&i.AvatarUrl,
+		&i.PinnedChirpID,
 	)
 	return i, err
 }
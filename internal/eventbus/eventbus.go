@@ -0,0 +1,82 @@
+// Package eventbus delivers events to every chirpy server, so that state kept
+// in memory, such as the subscribers of live streams, can follow changes made
+// through any of them.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Event is a change that every server is told about. Data is the JSON payload
+// of the event; its shape depends on Type. ID is assigned when the event is
+// published and identifies it on every server sharing the bus.
+type Event struct {
+	ID   uint64          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Handler is called with each event published on a Bus.
+type Handler func(Event)
+
+// Bus publishes events to the handlers subscribed on every server sharing it.
+type Bus interface {
+	// Publish assigns event an ID and sends it to the subscribers. Delivery
+	// is at most once: a server that is disconnected from the bus misses the
+	// event.
+	Publish(ctx context.Context, event Event) error
+	// Subscribe registers handler for every event published from now on.
+	// Handlers are called one event at a time, in the order events were
+	// published.
+	Subscribe(handler Handler)
+}
+
+// handlers is the set of handlers subscribed on a Bus.
+type handlers struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func (h *handlers) Subscribe(handler Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.handlers = append(h.handlers, handler)
+}
+
+func (h *handlers) dispatch(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, handler := range h.handlers {
+		handler(event)
+	}
+}
+
+// Local is a Bus for a single server. Publish calls the handlers before it
+// returns, which also makes it convenient in tests.
+type Local struct {
+	handlers
+	// mu serializes publishing so that handlers see one event at a time.
+	mu     sync.Mutex
+	lastID uint64
+}
+
+// NewLocal returns an in-process Bus. Event IDs start from the current time
+// so that they keep increasing across restarts.
+func NewLocal() *Local {
+	return &Local{lastID: uint64(time.Now().UnixMicro())}
+}
+
+func (l *Local) Publish(ctx context.Context, event Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lastID++
+	event.ID = l.lastID
+	l.dispatch(event)
+	return nil
+}
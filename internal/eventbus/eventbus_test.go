@@ -0,0 +1,57 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	bus := NewLocal()
+	var first, second []string
+	var ids []uint64
+	bus.Subscribe(func(event Event) {
+		first = append(first, event.Type)
+		ids = append(ids, event.ID)
+	})
+	bus.Subscribe(func(event Event) { second = append(second, event.Type) })
+
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "a", Data: json.RawMessage(`{}`)}))
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "b", Data: json.RawMessage(`{}`)}))
+
+	assert.Equal(t, []string{"a", "b"}, first)
+	assert.Equal(t, []string{"a", "b"}, second)
+	require.Len(t, ids, 2)
+	assert.Equal(t, ids[0]+1, ids[1], "events are numbered in order")
+}
+
+func TestEncodeEvent(t *testing.T) {
+	t.Run("Round Trip", func(t *testing.T) {
+		payload, err := encodeEvent(Event{ID: 7, Type: "chirp_created", Data: json.RawMessage(`{"id":"1"}`)})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":7,"type":"chirp_created","data":{"id":"1"}}`, payload)
+
+		var event Event
+		require.NoError(t, json.Unmarshal([]byte(payload), &event))
+		assert.Equal(t, uint64(7), event.ID)
+		assert.Equal(t, "chirp_created", event.Type)
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		data, err := json.Marshal(strings.Repeat("a", maxPayloadSize))
+		require.NoError(t, err)
+		_, err = encodeEvent(Event{Type: "big", Data: data})
+		assert.ErrorIs(t, err, ErrPayloadTooLarge)
+	})
+}
+
+func TestEnqueue(t *testing.T) {
+	queue := make(chan Event, 1)
+	assert.True(t, enqueue(queue, Event{ID: 1}))
+	assert.False(t, enqueue(queue, Event{ID: 2}), "a full queue drops the event")
+	assert.Equal(t, uint64(1), (<-queue).ID)
+}
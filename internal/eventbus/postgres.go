@@ -0,0 +1,159 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxPayloadSize is the largest payload NOTIFY accepts in the default
+// configuration.
+const maxPayloadSize = 8000 - 1
+
+const (
+	minBackoff = 500 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+// queueSize is the number of received events that may wait for the handlers
+// before further events are dropped.
+const queueSize = 1024
+
+// ErrPayloadTooLarge is returned when an encoded event is too large to be
+// sent with NOTIFY. Events should carry IDs rather than whole records.
+var ErrPayloadTooLarge = errors.New("event payload is too large")
+
+// Postgres is a Bus that sends events with NOTIFY on a channel and receives
+// them with LISTEN, so it reaches every server connected to the database,
+// including the one that published the event. Event IDs are taken from a
+// sequence, so they are unique across servers. Events are only received while
+// Listen is running.
+type Postgres struct {
+	handlers
+	pool     *pgxpool.Pool
+	channel  string
+	sequence string
+}
+
+// NewPostgres returns a Bus on channel that publishes through pool and
+// numbers events with sequence. Call Listen to receive events.
+func NewPostgres(pool *pgxpool.Pool, channel, sequence string) *Postgres {
+	return &Postgres{pool: pool, channel: channel, sequence: sequence}
+}
+
+// encodeEvent returns the NOTIFY payload of event.
+func encodeEvent(event Event) (string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", fmt.Errorf("encode %s event: %w", event.Type, err)
+	}
+	if len(payload) > maxPayloadSize {
+		return "", fmt.Errorf("encode %s event: %w", event.Type, ErrPayloadTooLarge)
+	}
+	return string(payload), nil
+}
+
+func (p *Postgres) Publish(ctx context.Context, event Event) error {
+	err := p.pool.QueryRow(ctx, "SELECT nextval($1::regclass)", p.sequence).Scan(&event.ID)
+	if err != nil {
+		return fmt.Errorf("number %s event: %w", event.Type, err)
+	}
+	payload, err := encodeEvent(event)
+	if err != nil {
+		return err
+	}
+	_, err = p.pool.Exec(ctx, "SELECT pg_notify($1, $2)", p.channel, payload)
+	if err != nil {
+		return fmt.Errorf("publish %s event: %w", event.Type, err)
+	}
+	return nil
+}
+
+// Listen receives events on a dedicated connection and passes them to the
+// handlers until ctx is done. LISTEN holds on to its connection, so it is not
+// taken from the pool. When the connection is lost, Listen reconnects and
+// subscribes again with exponential backoff; events published in between
+// are missed.
+//
+// Handlers are called on a separate goroutine, so that handlers that query
+// the database do not hold up receiving. Events that arrive while the queue
+// to it is full are dropped.
+func (p *Postgres) Listen(ctx context.Context) {
+	queue := make(chan Event, queueSize)
+	defer close(queue)
+	go p.deliver(queue)
+
+	backoff := minBackoff
+	for {
+		err := p.listen(ctx, queue, func() { backoff = minBackoff })
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("Event bus listener disconnected, reconnecting in %v: %v\n", backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// listen connects, subscribes and queues events until the connection fails.
+// subscribed is called once notifications are being received.
+func (p *Postgres) listen(ctx context.Context, queue chan<- Event, subscribed func()) error {
+	conn, err := pgx.ConnectConfig(ctx, p.pool.Config().ConnConfig)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{p.channel}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	subscribed()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+
+		var event Event
+		err = json.Unmarshal([]byte(notification.Payload), &event)
+		if err != nil {
+			log.Printf("Error decoding event %q: %v\n", notification.Payload, err)
+			continue
+		}
+		if !enqueue(queue, event) {
+			log.Printf("Error queueing %s event %d: queue is full\n", event.Type, event.ID)
+		}
+	}
+}
+
+// deliver passes the events in queue to the handlers, in order, until queue
+// is closed.
+func (p *Postgres) deliver(queue <-chan Event) {
+	for event := range queue {
+		p.dispatch(event)
+	}
+}
+
+// enqueue adds event to queue without waiting, reporting whether there was
+// room for it.
+func enqueue(queue chan<- Event, event Event) bool {
+	select {
+	case queue <- event:
+		return true
+	default:
+		return false
+	}
+}
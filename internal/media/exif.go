@@ -0,0 +1,128 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+const orientationTag = 0x0112
+
+// exifOrientation returns the EXIF orientation of a JPEG image, from 1
+// (upright) to 8, or 1 if data has none.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		// Markers without a length: padding, TEM and RSTn.
+		if marker == 0xFF || marker == 0x01 || marker >= 0xD0 && marker <= 0xD7 {
+			i += 2
+			continue
+		}
+		// Metadata comes before the image data.
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			break
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of the TIFF
+// structure embedded in an EXIF segment.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for k := range entries {
+		entry := ifd + 2 + 12*k
+		if entry+12 > len(tiff) {
+			break
+		}
+		const typeShort = 3
+		if order.Uint16(tiff[entry:]) == orientationTag && order.Uint16(tiff[entry+2:]) == typeShort {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient transforms img, stored with the given EXIF orientation, so that it
+// is upright.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90° counterclockwise, so turn it clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90° clockwise, so turn it counterclockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):][:4], src.Pix[src.PixOffset(x, y):][:4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,322 @@
+// Package media validates uploaded images and prepares them for serving:
+// images are re-encoded, which drops EXIF and other metadata, and a
+// thumbnail is generated for each.
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+const (
+	// MaxBytes is the largest upload accepted.
+	MaxBytes = 5 << 20
+	// MaxPixels bounds the decoded size of an image, so that a small file
+	// cannot expand into gigabytes of memory.
+	MaxPixels = 40_000_000
+	// MaxFrames bounds the number of frames in an animated GIF. Together
+	// with MaxPixels, which also bounds the pixels of all frames combined,
+	// it keeps a small animation from expanding into gigabytes of memory.
+	MaxFrames = 500
+	// ThumbnailSize is the largest width and height of a thumbnail.
+	ThumbnailSize = 400
+
+	jpegQuality = 85
+)
+
+var (
+	// ErrUnsupported is returned for files that are not JPEG, PNG, GIF or
+	// WebP images.
+	ErrUnsupported = errors.New("unsupported media type, expected a JPEG, PNG, GIF or WebP image")
+	// ErrTooLarge is returned for images with more than MaxPixels pixels
+	// and animations with more than MaxFrames frames.
+	ErrTooLarge = errors.New("image dimensions are too large")
+)
+
+// File is an encoded image ready to be stored.
+type File struct {
+	ContentType string
+	// Ext is the file extension matching ContentType, including the dot.
+	Ext  string
+	Data []byte
+}
+
+// Image is a processed upload.
+type Image struct {
+	Original  File
+	Thumbnail File
+	Width     int
+	Height    int
+}
+
+// Process sniffs the format of data, ignoring any declared content type,
+// and re-encodes it without metadata. JPEG images are rotated upright
+// according to their EXIF orientation first, since the orientation is lost
+// with the rest of the metadata. WebP images are converted to PNG, as there
+// is no WebP encoder.
+func Process(data []byte) (*Image, error) {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return processJPEG(data)
+	case "image/png":
+		return processStill(data, png.Decode)
+	case "image/webp":
+		return processStill(data, webp.Decode)
+	case "image/gif":
+		return processGIF(data)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// checkSize rejects images that are empty or, judging by their header, too
+// large to decode.
+func checkSize(config image.Config) error {
+	if config.Width <= 0 || config.Height <= 0 {
+		return ErrUnsupported
+	}
+	if config.Width*config.Height > MaxPixels {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+func processJPEG(data []byte) (*Image, error) {
+	config, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if err := checkSize(config); err != nil {
+		return nil, err
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	img = orient(img, exifOrientation(data))
+
+	original, err := encodeJPEG(img)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := encodeJPEG(thumbnail(img))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	return &Image{Original: original, Thumbnail: thumb, Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+func processStill(data []byte, decode func(r io.Reader) (image.Image, error)) (*Image, error) {
+	img, err := decodeChecked(data, decode)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := encodePNG(img)
+	if err != nil {
+		return nil, err
+	}
+	thumb, err := encodePNG(thumbnail(img))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	return &Image{Original: original, Thumbnail: thumb, Width: bounds.Dx(), Height: bounds.Dy()}, nil
+}
+
+// decodeChecked decodes a PNG or WebP image after checking its size. Both
+// formats share image.DecodeConfig, which dispatches on the file header.
+func decodeChecked(data []byte, decode func(r io.Reader) (image.Image, error)) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if err := checkSize(config); err != nil {
+		return nil, err
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	return img, nil
+}
+
+// processGIF keeps animations: every frame is re-encoded, which drops
+// comments and application extensions other than the loop count. The
+// thumbnail is a PNG of the first frame.
+func processGIF(data []byte) (*Image, error) {
+	config, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if err := checkSize(config); err != nil {
+		return nil, err
+	}
+	// gif.DecodeAll keeps every frame in memory, so count them first.
+	if err := checkFrames(data); err != nil {
+		return nil, err
+	}
+
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil || len(anim.Image) == 0 {
+		return nil, ErrUnsupported
+	}
+
+	var buf bytes.Buffer
+	err = gif.EncodeAll(&buf, anim)
+	if err != nil {
+		return nil, err
+	}
+	original := File{ContentType: "image/gif", Ext: ".gif", Data: buf.Bytes()}
+
+	// Frames may cover only part of the canvas, so draw the first one onto
+	// a canvas of the full size.
+	first := image.NewRGBA(image.Rect(0, 0, config.Width, config.Height))
+	draw.Draw(first, anim.Image[0].Bounds(), anim.Image[0], anim.Image[0].Bounds().Min, draw.Src)
+	thumb, err := encodePNG(thumbnail(first))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Image{Original: original, Thumbnail: thumb, Width: config.Width, Height: config.Height}, nil
+}
+
+// checkFrames walks the blocks of a GIF without decoding any pixels and
+// rejects animations with more than MaxFrames frames or more than MaxPixels
+// pixels across all of their frames.
+func checkFrames(data []byte) error {
+	r := gifReader{data: data}
+	// The header is followed by the logical screen descriptor, whose packed
+	// field says whether a global color table follows.
+	screen, ok := r.next(13)
+	if !ok {
+		return ErrUnsupported
+	}
+	if screen[10]&0x80 != 0 && !r.skip(3<<(screen[10]&0x07+1)) {
+		return ErrUnsupported
+	}
+
+	frames, pixels := 0, 0
+	for {
+		block, ok := r.next(1)
+		if !ok {
+			return ErrUnsupported
+		}
+		switch block[0] {
+		case 0x21: // Extension: a label and data sub-blocks.
+			if !r.skip(1) || !r.skipSubBlocks() {
+				return ErrUnsupported
+			}
+		case 0x2c: // Image descriptor: position, size and packed field.
+			desc, ok := r.next(9)
+			if !ok {
+				return ErrUnsupported
+			}
+			frames++
+			pixels += int(binary.LittleEndian.Uint16(desc[4:])) * int(binary.LittleEndian.Uint16(desc[6:]))
+			if frames > MaxFrames || pixels > MaxPixels {
+				return ErrTooLarge
+			}
+			if desc[8]&0x80 != 0 && !r.skip(3<<(desc[8]&0x07+1)) {
+				return ErrUnsupported
+			}
+			// The LZW minimum code size, then the image data sub-blocks.
+			if !r.skip(1) || !r.skipSubBlocks() {
+				return ErrUnsupported
+			}
+		case 0x3b: // Trailer.
+			return nil
+		default:
+			return ErrUnsupported
+		}
+	}
+}
+
+// gifReader reads the blocks of a GIF from a byte slice.
+type gifReader struct {
+	data []byte
+}
+
+// next returns the next n bytes, or false if there are fewer left.
+func (r *gifReader) next(n int) ([]byte, bool) {
+	if len(r.data) < n {
+		return nil, false
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b, true
+}
+
+func (r *gifReader) skip(n int) bool {
+	_, ok := r.next(n)
+	return ok
+}
+
+// skipSubBlocks skips data sub-blocks up to and including the empty block
+// that terminates them.
+func (r *gifReader) skipSubBlocks() bool {
+	for {
+		size, ok := r.next(1)
+		if !ok {
+			return false
+		}
+		if size[0] == 0 {
+			return true
+		}
+		if !r.skip(int(size[0])) {
+			return false
+		}
+	}
+}
+
+// thumbnail scales img down to fit in a ThumbnailSize square, keeping its
+// aspect ratio. Images that already fit are returned unchanged.
+func thumbnail(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= ThumbnailSize && h <= ThumbnailSize {
+		return img
+	}
+
+	if w >= h {
+		w, h = ThumbnailSize, max(1, h*ThumbnailSize/w)
+	} else {
+		w, h = max(1, w*ThumbnailSize/h), ThumbnailSize
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func encodeJPEG(img image.Image) (File, error) {
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	if err != nil {
+		return File{}, err
+	}
+	return File{ContentType: "image/jpeg", Ext: ".jpg", Data: buf.Bytes()}, nil
+}
+
+func encodePNG(img image.Image) (File, error) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		return File{}, err
+	}
+	return File{ContentType: "image/png", Ext: ".png", Data: buf.Bytes()}, nil
+}
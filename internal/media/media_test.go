@@ -0,0 +1,180 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{uint8(x * 10), uint8(y * 10), 0, 255})
+		}
+	}
+	return img
+}
+
+// withEXIFOrientation inserts an EXIF segment with the given orientation
+// right after the start of a JPEG image.
+func withEXIFOrientation(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+
+	var tiff bytes.Buffer
+	tiff.WriteString("MM")
+	binary.Write(&tiff, binary.BigEndian, uint16(42))
+	binary.Write(&tiff, binary.BigEndian, uint32(8))
+	binary.Write(&tiff, binary.BigEndian, uint16(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{orientationTag, 3})
+	binary.Write(&tiff, binary.BigEndian, uint32(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{orientation, 0})
+	binary.Write(&tiff, binary.BigEndian, uint32(0))
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	var out bytes.Buffer
+	out.Write(jpg[:2])
+	out.Write([]byte{0xFF, 0xE1})
+	binary.Write(&out, binary.BigEndian, uint16(len(segment)+2))
+	out.Write(segment)
+	out.Write(jpg[2:])
+	return out.Bytes()
+}
+
+func TestProcessJPEG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(40, 20), nil))
+	data := withEXIFOrientation(t, buf.Bytes(), 6)
+	require.Equal(t, 6, exifOrientation(data))
+
+	img, err := Process(data)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", img.Original.ContentType)
+	assert.Equal(t, ".jpg", img.Original.Ext)
+	assert.Equal(t, 20, img.Width, "rotated upright")
+	assert.Equal(t, 40, img.Height, "rotated upright")
+	assert.NotContains(t, string(img.Original.Data), "Exif")
+	assert.Equal(t, 1, exifOrientation(img.Original.Data))
+
+	decoded, err := jpeg.Decode(bytes.NewReader(img.Original.Data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 20, 40), decoded.Bounds())
+}
+
+func TestOrient(t *testing.T) {
+	src := testImage(3, 2)
+	// The pixel at the top left of the upright image, for each orientation.
+	topLeft := map[int]image.Point{1: {0, 0}, 2: {2, 0}, 3: {2, 1}, 4: {0, 1}, 5: {0, 0}, 6: {0, 1}, 7: {2, 1}, 8: {2, 0}}
+	for orientation, p := range topLeft {
+		dst := orient(src, orientation)
+		assert.Equal(t, src.At(p.X, p.Y), dst.At(0, 0), "orientation %d", orientation)
+		if orientation >= 5 {
+			assert.Equal(t, image.Rect(0, 0, 2, 3), dst.Bounds())
+		}
+	}
+}
+
+func TestProcessPNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, testImage(1000, 500)))
+
+	img, err := Process(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", img.Original.ContentType)
+	assert.Equal(t, 1000, img.Width)
+
+	thumb, err := png.DecodeConfig(bytes.NewReader(img.Thumbnail.Data))
+	require.NoError(t, err)
+	assert.Equal(t, ThumbnailSize, thumb.Width)
+	assert.Equal(t, ThumbnailSize/2, thumb.Height)
+}
+
+func TestProcessGIF(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{
+		Image: []*image.Paletted{image.NewPaletted(image.Rect(0, 0, 10, 10), palette), image.NewPaletted(image.Rect(0, 0, 10, 10), palette)},
+		Delay: []int{10, 10},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+
+	img, err := Process(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "image/gif", img.Original.ContentType)
+	assert.Equal(t, "image/png", img.Thumbnail.ContentType)
+
+	decoded, err := gif.DecodeAll(bytes.NewReader(img.Original.Data))
+	require.NoError(t, err)
+	assert.Len(t, decoded.Image, 2, "animation is kept")
+}
+
+func TestProcessRejects(t *testing.T) {
+	t.Run("Not An Image", func(t *testing.T) {
+		_, err := Process([]byte("<html><body>hello</body></html>"))
+		assert.ErrorIs(t, err, ErrUnsupported)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, testImage(10, 10)))
+		_, err := Process(buf.Bytes()[:40])
+		assert.ErrorIs(t, err, ErrUnsupported)
+	})
+
+	t.Run("Too Many Pixels", func(t *testing.T) {
+		// A PNG header claiming 10000x10000 pixels.
+		ihdr := binary.BigEndian.AppendUint32(nil, 10000)
+		ihdr = binary.BigEndian.AppendUint32(ihdr, 10000)
+		ihdr = append(ihdr, 8, 2, 0, 0, 0)
+		chunk := append([]byte("IHDR"), ihdr...)
+
+		data := []byte("\x89PNG\r\n\x1a\n")
+		data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)))
+		data = append(data, chunk...)
+		data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(chunk))
+
+		_, err := Process(data)
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+	t.Run("Too Many Frames", func(t *testing.T) {
+		palette := color.Palette{color.Black, color.White}
+		anim := &gif.GIF{}
+		for range MaxFrames + 1 {
+			anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, 1, 1), palette))
+			anim.Delay = append(anim.Delay, 10)
+		}
+		var buf bytes.Buffer
+		require.NoError(t, gif.EncodeAll(&buf, anim))
+
+		_, err := Process(buf.Bytes())
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+
+	t.Run("Too Many Pixels Across Frames", func(t *testing.T) {
+		// A 6000x6000 GIF with two full-size frames, each claiming no
+		// pixel data: the frames are rejected before any is decoded.
+		data := []byte("GIF89a")
+		data = binary.LittleEndian.AppendUint16(data, 6000)
+		data = binary.LittleEndian.AppendUint16(data, 6000)
+		data = append(data, 0, 0, 0)
+		for range 2 {
+			data = append(data, 0x2c, 0, 0, 0, 0)
+			data = binary.LittleEndian.AppendUint16(data, 6000)
+			data = binary.LittleEndian.AppendUint16(data, 6000)
+			data = append(data, 0, 2, 0)
+		}
+		data = append(data, 0x3b)
+
+		_, err := Process(data)
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+}
@@ -0,0 +1,180 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type blockKey struct {
+	blockerID pgtype.UUID
+	blockedID pgtype.UUID
+}
+
+type muteKey struct {
+	muterID pgtype.UUID
+	mutedID pgtype.UUID
+}
+
+// hidden reports whether userID's chirps are hidden from viewer, like the
+// hidden_users function: either blocked the other, or viewer muted userID.
+// Nothing is hidden from an invalid viewer. The caller must hold s.mu.
+func (s *Store) hidden(viewer, userID pgtype.UUID) bool {
+	if !viewer.Valid {
+		return false
+	}
+	_, blocked := s.blocks[blockKey{blockerID: viewer, blockedID: userID}]
+	_, blocking := s.blocks[blockKey{blockerID: userID, blockedID: viewer}]
+	_, muted := s.mutes[muteKey{muterID: viewer, mutedID: userID}]
+	return blocked || blocking || muted
+}
+
+func (s *Store) CreateBlock(ctx context.Context, arg database.CreateBlockParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.BlockerID]; !ok {
+		return 0, foreignKeyViolation("blocks", "blocks_blocker_id_fkey")
+	}
+	if _, ok := s.users[arg.BlockedID]; !ok {
+		return 0, foreignKeyViolation("blocks", "blocks_blocked_id_fkey")
+	}
+	if arg.BlockerID == arg.BlockedID {
+		return 0, checkViolation("blocks", "blocks_no_self_block")
+	}
+
+	delete(s.follows, followKey{followerID: arg.BlockerID, followeeID: arg.BlockedID})
+	delete(s.follows, followKey{followerID: arg.BlockedID, followeeID: arg.BlockerID})
+
+	key := blockKey{blockerID: arg.BlockerID, blockedID: arg.BlockedID}
+	if _, ok := s.blocks[key]; ok {
+		return 0, nil
+	}
+	s.blocks[key] = database.Block{BlockerID: arg.BlockerID, BlockedID: arg.BlockedID, CreatedAt: s.now()}
+	return 1, nil
+}
+
+func (s *Store) DeleteBlock(ctx context.Context, arg database.DeleteBlockParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := blockKey{blockerID: arg.BlockerID, blockedID: arg.BlockedID}
+	if _, ok := s.blocks[key]; !ok {
+		return 0, nil
+	}
+	delete(s.blocks, key)
+	return 1, nil
+}
+
+func (s *Store) GetBlocks(ctx context.Context, arg database.GetBlocksParams) ([]database.GetBlocksRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var blocks []database.Block
+	for _, block := range s.blocks {
+		if block.BlockerID == arg.UserID && compareKey(block.CreatedAt, block.BlockedID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			blocks = append(blocks, block)
+		}
+	}
+	blocks = sortAndLimit(blocks, func(block database.Block) (pgtype.Timestamp, pgtype.UUID) {
+		return block.CreatedAt, block.BlockedID
+	}, arg.PageSize, true)
+
+	var rows []database.GetBlocksRow
+	for _, block := range blocks {
+		rows = append(rows, database.GetBlocksRow{User: s.users[block.BlockedID], BlockedAt: block.CreatedAt})
+	}
+	return rows, nil
+}
+
+func (s *Store) IsBlocked(ctx context.Context, arg database.IsBlockedParams) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, blocked := s.blocks[blockKey{blockerID: arg.UserID, blockedID: arg.OtherID}]
+	_, blocking := s.blocks[blockKey{blockerID: arg.OtherID, blockedID: arg.UserID}]
+	return blocked || blocking, nil
+}
+
+func (s *Store) CreateMute(ctx context.Context, arg database.CreateMuteParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.MuterID]; !ok {
+		return 0, foreignKeyViolation("mutes", "mutes_muter_id_fkey")
+	}
+	if _, ok := s.users[arg.MutedID]; !ok {
+		return 0, foreignKeyViolation("mutes", "mutes_muted_id_fkey")
+	}
+	if arg.MuterID == arg.MutedID {
+		return 0, checkViolation("mutes", "mutes_no_self_mute")
+	}
+
+	key := muteKey{muterID: arg.MuterID, mutedID: arg.MutedID}
+	if _, ok := s.mutes[key]; ok {
+		return 0, nil
+	}
+	s.mutes[key] = database.Mute{MuterID: arg.MuterID, MutedID: arg.MutedID, CreatedAt: s.now()}
+	return 1, nil
+}
+
+func (s *Store) DeleteMute(ctx context.Context, arg database.DeleteMuteParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := muteKey{muterID: arg.MuterID, mutedID: arg.MutedID}
+	if _, ok := s.mutes[key]; !ok {
+		return 0, nil
+	}
+	delete(s.mutes, key)
+	return 1, nil
+}
+
+func (s *Store) GetMutes(ctx context.Context, arg database.GetMutesParams) ([]database.GetMutesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var mutes []database.Mute
+	for _, mute := range s.mutes {
+		if mute.MuterID == arg.UserID && compareKey(mute.CreatedAt, mute.MutedID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			mutes = append(mutes, mute)
+		}
+	}
+	mutes = sortAndLimit(mutes, func(mute database.Mute) (pgtype.Timestamp, pgtype.UUID) {
+		return mute.CreatedAt, mute.MutedID
+	}, arg.PageSize, true)
+
+	var rows []database.GetMutesRow
+	for _, mute := range mutes {
+		rows = append(rows, database.GetMutesRow{User: s.users[mute.MutedID], MutedAt: mute.CreatedAt})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetAllHiddenUserIDs(ctx context.Context, viewerID pgtype.UUID) ([]pgtype.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []pgtype.UUID
+	for id := range s.users {
+		if s.hidden(viewerID, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (s *Store) GetHiddenUserIDs(ctx context.Context, arg database.GetHiddenUserIDsParams) ([]pgtype.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []pgtype.UUID
+	for _, id := range arg.UserIds {
+		if s.hidden(arg.ViewerID, id) && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,81 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type bookmarkKey struct {
+	userID  pgtype.UUID
+	chirpID pgtype.UUID
+}
+
+func (s *Store) CreateBookmark(ctx context.Context, arg database.CreateBookmarkParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return 0, foreignKeyViolation("bookmarks", "bookmarks_user_id_fkey")
+	}
+	if _, ok := s.chirps[arg.ChirpID]; !ok {
+		return 0, foreignKeyViolation("bookmarks", "bookmarks_chirp_id_fkey")
+	}
+
+	key := bookmarkKey{userID: arg.UserID, chirpID: arg.ChirpID}
+	if _, ok := s.bookmarks[key]; ok {
+		return 0, nil
+	}
+	s.bookmarks[key] = database.Bookmark{UserID: arg.UserID, ChirpID: arg.ChirpID, CreatedAt: s.now()}
+	return 1, nil
+}
+
+func (s *Store) DeleteBookmark(ctx context.Context, arg database.DeleteBookmarkParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := bookmarkKey{userID: arg.UserID, chirpID: arg.ChirpID}
+	if _, ok := s.bookmarks[key]; !ok {
+		return 0, nil
+	}
+	delete(s.bookmarks, key)
+	return 1, nil
+}
+
+func (s *Store) GetBookmarks(ctx context.Context, arg database.GetBookmarksParams) ([]database.GetBookmarksRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var bookmarks []database.Bookmark
+	for _, bookmark := range s.bookmarks {
+		chirp := s.chirps[bookmark.ChirpID]
+		if bookmark.UserID == arg.UserID && !s.hidden(arg.UserID, chirp.UserID) && !expired(chirp) &&
+			compareKey(bookmark.CreatedAt, bookmark.ChirpID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			bookmarks = append(bookmarks, bookmark)
+		}
+	}
+	bookmarks = sortAndLimit(bookmarks, func(bookmark database.Bookmark) (pgtype.Timestamp, pgtype.UUID) {
+		return bookmark.CreatedAt, bookmark.ChirpID
+	}, arg.PageSize, true)
+
+	var rows []database.GetBookmarksRow
+	for _, bookmark := range bookmarks {
+		rows = append(rows, database.GetBookmarksRow{Chirp: s.chirps[bookmark.ChirpID], BookmarkedAt: bookmark.CreatedAt})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetBookmarkedChirpIDs(ctx context.Context, arg database.GetBookmarkedChirpIDsParams) ([]pgtype.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []pgtype.UUID
+	for key := range s.bookmarks {
+		if key.userID == arg.UserID && slices.Contains(arg.ChirpIds, key.chirpID) {
+			ids = append(ids, key.chirpID)
+		}
+	}
+	return ids, nil
+}
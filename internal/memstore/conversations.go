@@ -0,0 +1,241 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type participantKey struct {
+	conversationID pgtype.UUID
+	userID         pgtype.UUID
+}
+
+func (s *Store) CreateConversation(ctx context.Context, arg database.CreateConversationParams) (database.Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if arg.DirectKey.Valid {
+		for _, conversation := range s.conversations {
+			if conversation.DirectKey == arg.DirectKey {
+				return database.Conversation{}, pgx.ErrNoRows
+			}
+		}
+	}
+	for i, userID := range arg.ParticipantIds {
+		if _, ok := s.users[userID]; !ok {
+			return database.Conversation{}, foreignKeyViolation("conversation_participants", "conversation_participants_user_id_fkey")
+		}
+		if slices.Contains(arg.ParticipantIds[:i], userID) {
+			return database.Conversation{}, uniqueViolation("conversation_participants_pkey")
+		}
+	}
+
+	timestamp := s.now()
+	conversation := database.Conversation{
+		ID:        newUUID(),
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+		DirectKey: arg.DirectKey,
+	}
+	s.conversations[conversation.ID] = conversation
+	for _, userID := range arg.ParticipantIds {
+		s.participants[participantKey{conversationID: conversation.ID, userID: userID}] = database.ConversationParticipant{
+			ConversationID: conversation.ID,
+			UserID:         userID,
+			CreatedAt:      timestamp,
+		}
+	}
+	return conversation, nil
+}
+
+func (s *Store) GetDirectConversation(ctx context.Context, directKey pgtype.Text) (database.Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conversation := range s.conversations {
+		if directKey.Valid && conversation.DirectKey == directKey {
+			return conversation, nil
+		}
+	}
+	return database.Conversation{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetConversation(ctx context.Context, arg database.GetConversationParams) (database.Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.participants[participantKey{conversationID: arg.ID, userID: arg.UserID}]; !ok {
+		return database.Conversation{}, pgx.ErrNoRows
+	}
+	return s.conversations[arg.ID], nil
+}
+
+func (s *Store) GetConversations(ctx context.Context, arg database.GetConversationsParams) ([]database.Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var conversations []database.Conversation
+	for key := range s.participants {
+		conversation := s.conversations[key.conversationID]
+		if key.userID == arg.UserID && compareKey(conversation.UpdatedAt, conversation.ID, arg.CursorUpdatedAt, arg.CursorID) < 0 {
+			conversations = append(conversations, conversation)
+		}
+	}
+	return sortAndLimit(conversations, func(conversation database.Conversation) (pgtype.Timestamp, pgtype.UUID) {
+		return conversation.UpdatedAt, conversation.ID
+	}, arg.PageSize, true), nil
+}
+
+func (s *Store) GetConversationParticipants(ctx context.Context, conversationIds []pgtype.UUID) ([]database.GetConversationParticipantsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var participants []database.ConversationParticipant
+	for key, participant := range s.participants {
+		if slices.Contains(conversationIds, key.conversationID) {
+			participants = append(participants, participant)
+		}
+	}
+	slices.SortFunc(participants, func(a, b database.ConversationParticipant) int {
+		return compareKey(a.CreatedAt, a.UserID, b.CreatedAt, b.UserID)
+	})
+
+	var rows []database.GetConversationParticipantsRow
+	for _, participant := range participants {
+		rows = append(rows, database.GetConversationParticipantsRow{
+			ConversationID: participant.ConversationID,
+			User:           s.users[participant.UserID],
+			LastReadAt:     participant.LastReadAt,
+		})
+	}
+	return rows, nil
+}
+
+func (s *Store) CreateMessage(ctx context.Context, arg database.CreateMessageParams) (database.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := participantKey{conversationID: arg.ConversationID, userID: arg.UserID}
+	participant, ok := s.participants[key]
+	if !ok {
+		return database.Message{}, pgx.ErrNoRows
+	}
+
+	message := database.Message{
+		ID:             newUUID(),
+		CreatedAt:      s.now(),
+		ConversationID: arg.ConversationID,
+		UserID:         arg.UserID,
+		Body:           arg.Body,
+	}
+	s.messages[message.ID] = message
+
+	conversation := s.conversations[arg.ConversationID]
+	conversation.UpdatedAt = message.CreatedAt
+	s.conversations[conversation.ID] = conversation
+	participant.LastReadAt = message.CreatedAt
+	s.participants[key] = participant
+	return message, nil
+}
+
+func (s *Store) GetMessages(ctx context.Context, arg database.GetMessagesParams) ([]database.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var messages []database.Message
+	for _, message := range s.messages {
+		if message.ConversationID == arg.ConversationID && compareKey(message.CreatedAt, message.ID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			messages = append(messages, message)
+		}
+	}
+	return sortAndLimit(messages, func(message database.Message) (pgtype.Timestamp, pgtype.UUID) {
+		return message.CreatedAt, message.ID
+	}, arg.PageSize, true), nil
+}
+
+func (s *Store) MarkConversationRead(ctx context.Context, arg database.MarkConversationReadParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := participantKey{conversationID: arg.ConversationID, userID: arg.UserID}
+	participant, ok := s.participants[key]
+	if !ok {
+		return 0, nil
+	}
+	for _, message := range s.messages {
+		if message.ConversationID == arg.ConversationID && (!participant.LastReadAt.Valid || message.CreatedAt.Time.After(participant.LastReadAt.Time)) {
+			participant.LastReadAt = message.CreatedAt
+		}
+	}
+	s.participants[key] = participant
+	return 1, nil
+}
+
+// unread reports whether message is unread by participant: sent by someone
+// else after the participant's read marker.
+func unread(participant database.ConversationParticipant, message database.Message) bool {
+	return message.ConversationID == participant.ConversationID && message.UserID != participant.UserID &&
+		(!participant.LastReadAt.Valid || message.CreatedAt.Time.After(participant.LastReadAt.Time))
+}
+
+func (s *Store) GetUnreadCounts(ctx context.Context, arg database.GetUnreadCountsParams) ([]database.GetUnreadCountsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.GetUnreadCountsRow
+	for _, conversationID := range arg.ConversationIds {
+		participant, ok := s.participants[participantKey{conversationID: conversationID, userID: arg.UserID}]
+		if !ok {
+			continue
+		}
+		var count int64
+		for _, message := range s.messages {
+			if unread(participant, message) {
+				count++
+			}
+		}
+		if count > 0 {
+			rows = append(rows, database.GetUnreadCountsRow{ConversationID: conversationID, UnreadCount: count})
+		}
+	}
+	return rows, nil
+}
+
+func (s *Store) CountUnreadMessages(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for key, participant := range s.participants {
+		if key.userID != userID {
+			continue
+		}
+		for _, message := range s.messages {
+			if unread(participant, message) {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) IsBlockedInConversation(ctx context.Context, arg database.IsBlockedInConversationParams) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.participants {
+		if key.conversationID != arg.ConversationID {
+			continue
+		}
+		_, blocked := s.blocks[blockKey{blockerID: arg.UserID, blockedID: key.userID}]
+		_, blocking := s.blocks[blockKey{blockerID: key.userID, blockedID: arg.UserID}]
+		if blocked || blocking {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,112 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (s *Store) CreateDraft(ctx context.Context, arg database.CreateDraftParams) (database.Draft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return database.Draft{}, foreignKeyViolation("drafts", "drafts_user_id_fkey")
+	}
+
+	timestamp := s.now()
+	draft := database.Draft{
+		ID:        newUUID(),
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+		UserID:    arg.UserID,
+		Body:      arg.Body,
+		QuoteOf:   arg.QuoteOf,
+		InReplyTo: arg.InReplyTo,
+	}
+	s.drafts[draft.ID] = draft
+	return draft, nil
+}
+
+func (s *Store) GetDraft(ctx context.Context, arg database.GetDraftParams) (database.Draft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[arg.ID]
+	if !ok || draft.UserID != arg.UserID {
+		return database.Draft{}, pgx.ErrNoRows
+	}
+	return draft, nil
+}
+
+func (s *Store) GetDrafts(ctx context.Context, arg database.GetDraftsParams) ([]database.Draft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var drafts []database.Draft
+	for _, draft := range s.drafts {
+		if draft.UserID == arg.UserID && compareKey(draft.UpdatedAt, draft.ID, arg.CursorUpdatedAt, arg.CursorID) < 0 {
+			drafts = append(drafts, draft)
+		}
+	}
+	return sortAndLimit(drafts, func(draft database.Draft) (pgtype.Timestamp, pgtype.UUID) {
+		return draft.UpdatedAt, draft.ID
+	}, arg.PageSize, true), nil
+}
+
+func (s *Store) UpdateDraft(ctx context.Context, arg database.UpdateDraftParams) (database.Draft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[arg.ID]
+	if !ok || draft.UserID != arg.UserID {
+		return database.Draft{}, pgx.ErrNoRows
+	}
+	draft.Body = arg.Body
+	draft.QuoteOf = arg.QuoteOf
+	draft.InReplyTo = arg.InReplyTo
+	draft.UpdatedAt = s.now()
+	s.drafts[arg.ID] = draft
+	return draft, nil
+}
+
+func (s *Store) DeleteDraft(ctx context.Context, arg database.DeleteDraftParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[arg.ID]
+	if !ok || draft.UserID != arg.UserID {
+		return 0, nil
+	}
+	delete(s.drafts, arg.ID)
+	return 1, nil
+}
+
+func (s *Store) PublishDraft(ctx context.Context, arg database.PublishDraftParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, ok := s.drafts[arg.DraftID]
+	if !ok || draft.UserID != arg.UserID {
+		return database.Chirp{}, pgx.ErrNoRows
+	}
+
+	timestamp := s.now()
+	chirp := database.Chirp{
+		ID:             newUUID(),
+		CreatedAt:      timestamp,
+		UpdatedAt:      timestamp,
+		Body:           arg.Body,
+		UserID:         draft.UserID,
+		QuoteOf:        arg.QuoteOf,
+		InReplyTo:      arg.InReplyTo,
+		ConversationID: arg.ConversationID,
+		AncestorIds:    slices.Clone(arg.AncestorIds),
+	}
+	s.chirps[chirp.ID] = chirp
+	delete(s.drafts, draft.ID)
+	return chirp, nil
+}
@@ -0,0 +1,116 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type followKey struct {
+	followerID pgtype.UUID
+	followeeID pgtype.UUID
+}
+
+func (s *Store) CreateFollow(ctx context.Context, arg database.CreateFollowParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.FollowerID]; !ok {
+		return 0, foreignKeyViolation("follows", "follows_follower_id_fkey")
+	}
+	if _, ok := s.users[arg.FolloweeID]; !ok {
+		return 0, foreignKeyViolation("follows", "follows_followee_id_fkey")
+	}
+	if arg.FollowerID == arg.FolloweeID {
+		return 0, checkViolation("follows", "follows_no_self_follow")
+	}
+
+	key := followKey{followerID: arg.FollowerID, followeeID: arg.FolloweeID}
+	if _, ok := s.follows[key]; ok {
+		return 0, nil
+	}
+	s.follows[key] = database.Follow{FollowerID: arg.FollowerID, FolloweeID: arg.FolloweeID, CreatedAt: s.now()}
+	return 1, nil
+}
+
+func (s *Store) DeleteFollow(ctx context.Context, arg database.DeleteFollowParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := followKey{followerID: arg.FollowerID, followeeID: arg.FolloweeID}
+	if _, ok := s.follows[key]; !ok {
+		return 0, nil
+	}
+	delete(s.follows, key)
+	return 1, nil
+}
+
+// pageFollows returns up to limit follow edges matching keep that come before
+// the cursor in (created_at, other) order, newest first, where other is the
+// user on the far side of the edge. The caller must hold s.mu.
+func (s *Store) pageFollows(keep func(database.Follow) bool, other func(database.Follow) pgtype.UUID, cursorCreatedAt pgtype.Timestamp, cursorID pgtype.UUID, limit int32) []database.Follow {
+	var follows []database.Follow
+	for _, follow := range s.follows {
+		if keep(follow) && compareKey(follow.CreatedAt, other(follow), cursorCreatedAt, cursorID) < 0 {
+			follows = append(follows, follow)
+		}
+	}
+	return sortAndLimit(follows, func(follow database.Follow) (pgtype.Timestamp, pgtype.UUID) {
+		return follow.CreatedAt, other(follow)
+	}, limit, true)
+}
+
+func (s *Store) GetFollowers(ctx context.Context, arg database.GetFollowersParams) ([]database.GetFollowersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	follows := s.pageFollows(
+		func(follow database.Follow) bool {
+			return follow.FolloweeID == arg.UserID && !s.hidden(arg.ViewerID, follow.FollowerID)
+		},
+		func(follow database.Follow) pgtype.UUID { return follow.FollowerID },
+		arg.CursorCreatedAt, arg.CursorID, arg.PageSize,
+	)
+
+	var rows []database.GetFollowersRow
+	for _, follow := range follows {
+		rows = append(rows, database.GetFollowersRow{User: s.users[follow.FollowerID], FollowedAt: follow.CreatedAt})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetFollowing(ctx context.Context, arg database.GetFollowingParams) ([]database.GetFollowingRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	follows := s.pageFollows(
+		func(follow database.Follow) bool {
+			return follow.FollowerID == arg.UserID && !s.hidden(arg.ViewerID, follow.FolloweeID)
+		},
+		func(follow database.Follow) pgtype.UUID { return follow.FolloweeID },
+		arg.CursorCreatedAt, arg.CursorID, arg.PageSize,
+	)
+
+	var rows []database.GetFollowingRow
+	for _, follow := range follows {
+		rows = append(rows, database.GetFollowingRow{User: s.users[follow.FolloweeID], FollowedAt: follow.CreatedAt})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetFollowCounts(ctx context.Context, userID pgtype.UUID) (database.GetFollowCountsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var counts database.GetFollowCountsRow
+	for key := range s.follows {
+		if key.followeeID == userID {
+			counts.FollowersCount++
+		}
+		if key.followerID == userID {
+			counts.FollowingCount++
+		}
+	}
+	return counts, nil
+}
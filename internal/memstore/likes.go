@@ -0,0 +1,121 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type likeKey struct {
+	userID  pgtype.UUID
+	chirpID pgtype.UUID
+}
+
+func (s *Store) CreateLike(ctx context.Context, arg database.CreateLikeParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return 0, foreignKeyViolation("likes", "likes_user_id_fkey")
+	}
+	if _, ok := s.chirps[arg.ChirpID]; !ok {
+		return 0, foreignKeyViolation("likes", "likes_chirp_id_fkey")
+	}
+
+	key := likeKey{userID: arg.UserID, chirpID: arg.ChirpID}
+	if _, ok := s.likes[key]; ok {
+		return 0, nil
+	}
+	s.likes[key] = database.Like{UserID: arg.UserID, ChirpID: arg.ChirpID, CreatedAt: s.now()}
+	return 1, nil
+}
+
+func (s *Store) DeleteLike(ctx context.Context, arg database.DeleteLikeParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := likeKey{userID: arg.UserID, chirpID: arg.ChirpID}
+	if _, ok := s.likes[key]; !ok {
+		return 0, nil
+	}
+	delete(s.likes, key)
+	return 1, nil
+}
+
+func (s *Store) GetLikeCounts(ctx context.Context, chirpIds []pgtype.UUID) ([]database.GetLikeCountsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[pgtype.UUID]int64)
+	for key := range s.likes {
+		if slices.Contains(chirpIds, key.chirpID) {
+			counts[key.chirpID]++
+		}
+	}
+
+	var rows []database.GetLikeCountsRow
+	for chirpID, count := range counts {
+		rows = append(rows, database.GetLikeCountsRow{ChirpID: chirpID, LikeCount: count})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetLikedChirpIDs(ctx context.Context, arg database.GetLikedChirpIDsParams) ([]pgtype.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []pgtype.UUID
+	for key := range s.likes {
+		if key.userID == arg.UserID && slices.Contains(arg.ChirpIds, key.chirpID) {
+			ids = append(ids, key.chirpID)
+		}
+	}
+	return ids, nil
+}
+
+func (s *Store) GetChirpLikes(ctx context.Context, arg database.GetChirpLikesParams) ([]database.GetChirpLikesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var likes []database.Like
+	for _, like := range s.likes {
+		if like.ChirpID == arg.ChirpID && !s.hidden(arg.ViewerID, like.UserID) &&
+			compareKey(like.CreatedAt, like.UserID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			likes = append(likes, like)
+		}
+	}
+	likes = sortAndLimit(likes, func(like database.Like) (pgtype.Timestamp, pgtype.UUID) {
+		return like.CreatedAt, like.UserID
+	}, arg.PageSize, true)
+
+	var rows []database.GetChirpLikesRow
+	for _, like := range likes {
+		rows = append(rows, database.GetChirpLikesRow{User: s.users[like.UserID], LikedAt: like.CreatedAt})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetUserLikes(ctx context.Context, arg database.GetUserLikesParams) ([]database.GetUserLikesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var likes []database.Like
+	for _, like := range s.likes {
+		chirp := s.chirps[like.ChirpID]
+		if like.UserID == arg.UserID && !s.hidden(arg.ViewerID, chirp.UserID) && !expired(chirp) &&
+			compareKey(like.CreatedAt, like.ChirpID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			likes = append(likes, like)
+		}
+	}
+	likes = sortAndLimit(likes, func(like database.Like) (pgtype.Timestamp, pgtype.UUID) {
+		return like.CreatedAt, like.ChirpID
+	}, arg.PageSize, true)
+
+	var rows []database.GetUserLikesRow
+	for _, like := range likes {
+		rows = append(rows, database.GetUserLikesRow{Chirp: s.chirps[like.ChirpID], LikedAt: like.CreatedAt})
+	}
+	return rows, nil
+}
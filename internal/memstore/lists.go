@@ -0,0 +1,192 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type listMemberKey struct {
+	listID pgtype.UUID
+	userID pgtype.UUID
+}
+
+func (s *Store) CreateList(ctx context.Context, arg database.CreateListParams) (database.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return database.List{}, foreignKeyViolation("lists", "lists_user_id_fkey")
+	}
+
+	timestamp := s.now()
+	list := database.List{
+		ID:          newUUID(),
+		CreatedAt:   timestamp,
+		UpdatedAt:   timestamp,
+		UserID:      arg.UserID,
+		Name:        arg.Name,
+		Description: arg.Description,
+		IsPrivate:   arg.IsPrivate,
+	}
+	s.lists[list.ID] = list
+	return list, nil
+}
+
+func (s *Store) GetList(ctx context.Context, id pgtype.UUID) (database.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.lists[id]
+	if !ok {
+		return database.List{}, pgx.ErrNoRows
+	}
+	return list, nil
+}
+
+func (s *Store) GetUserLists(ctx context.Context, arg database.GetUserListsParams) ([]database.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lists []database.List
+	for _, list := range s.lists {
+		if list.UserID == arg.UserID && (!list.IsPrivate || list.UserID == arg.ViewerID) &&
+			compareKey(list.CreatedAt, list.ID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			lists = append(lists, list)
+		}
+	}
+	return sortAndLimit(lists, func(list database.List) (pgtype.Timestamp, pgtype.UUID) {
+		return list.CreatedAt, list.ID
+	}, arg.PageSize, true), nil
+}
+
+func (s *Store) UpdateList(ctx context.Context, arg database.UpdateListParams) (database.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.lists[arg.ID]
+	if !ok || list.UserID != arg.UserID {
+		return database.List{}, pgx.ErrNoRows
+	}
+
+	list.Name = arg.Name
+	list.Description = arg.Description
+	list.IsPrivate = arg.IsPrivate
+	list.UpdatedAt = s.now()
+	s.lists[list.ID] = list
+	return list, nil
+}
+
+// deleteList removes a list together with its memberships, as ON DELETE
+// CASCADE does. The caller must hold s.mu.
+func (s *Store) deleteList(id pgtype.UUID) {
+	for key := range s.listMembers {
+		if key.listID == id {
+			delete(s.listMembers, key)
+		}
+	}
+	delete(s.lists, id)
+}
+
+func (s *Store) DeleteList(ctx context.Context, arg database.DeleteListParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.lists[arg.ID]
+	if !ok || list.UserID != arg.UserID {
+		return 0, nil
+	}
+	s.deleteList(list.ID)
+	return 1, nil
+}
+
+// LockList is a no-op: the store serializes every call with its mutex.
+func (s *Store) LockList(ctx context.Context, id pgtype.UUID) error {
+	return nil
+}
+
+func (s *Store) AddListMember(ctx context.Context, arg database.AddListMemberParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lists[arg.ListID]; !ok {
+		return 0, foreignKeyViolation("list_members", "list_members_list_id_fkey")
+	}
+	if _, ok := s.users[arg.UserID]; !ok {
+		return 0, foreignKeyViolation("list_members", "list_members_user_id_fkey")
+	}
+
+	key := listMemberKey{listID: arg.ListID, userID: arg.UserID}
+	if _, ok := s.listMembers[key]; ok {
+		return 0, nil
+	}
+	s.listMembers[key] = database.ListMember{ListID: arg.ListID, UserID: arg.UserID, CreatedAt: s.now()}
+	return 1, nil
+}
+
+func (s *Store) RemoveListMember(ctx context.Context, arg database.RemoveListMemberParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := listMemberKey{listID: arg.ListID, userID: arg.UserID}
+	if _, ok := s.listMembers[key]; !ok {
+		return 0, nil
+	}
+	delete(s.listMembers, key)
+	return 1, nil
+}
+
+func (s *Store) IsListMember(ctx context.Context, arg database.IsListMemberParams) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.listMembers[listMemberKey{listID: arg.ListID, userID: arg.UserID}]
+	return ok, nil
+}
+
+func (s *Store) CountListMembers(ctx context.Context, listID pgtype.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for key := range s.listMembers {
+		if key.listID == listID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *Store) GetListMembers(ctx context.Context, arg database.GetListMembersParams) ([]database.GetListMembersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var members []database.ListMember
+	for _, member := range s.listMembers {
+		if member.ListID == arg.ListID && compareKey(member.CreatedAt, member.UserID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			members = append(members, member)
+		}
+	}
+	members = sortAndLimit(members, func(member database.ListMember) (pgtype.Timestamp, pgtype.UUID) {
+		return member.CreatedAt, member.UserID
+	}, arg.PageSize, true)
+
+	var rows []database.GetListMembersRow
+	for _, member := range members {
+		rows = append(rows, database.GetListMembersRow{User: s.users[member.UserID], AddedAt: member.CreatedAt})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetListChirps(ctx context.Context, arg database.GetListChirpsParams) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inList := func(chirp database.Chirp) bool {
+		_, member := s.listMembers[listMemberKey{listID: arg.ListID, userID: chirp.UserID}]
+		return member && !s.hidden(arg.ViewerID, chirp.UserID)
+	}
+	return s.pageChirps(inList, arg.CursorCreatedAt, arg.CursorID, arg.PageSize, true), nil
+}
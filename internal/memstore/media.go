@@ -0,0 +1,111 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (s *Store) CreateMedia(ctx context.Context, arg database.CreateMediaParams) (database.Medium, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return database.Medium{}, foreignKeyViolation("media", "media_user_id_fkey")
+	}
+
+	medium := database.Medium{
+		ID:           newUUID(),
+		CreatedAt:    s.now(),
+		UserID:       arg.UserID,
+		ContentType:  arg.ContentType,
+		Width:        arg.Width,
+		Height:       arg.Height,
+		BlobKey:      arg.BlobKey,
+		ThumbnailKey: arg.ThumbnailKey,
+	}
+	s.media[medium.ID] = medium
+	return medium, nil
+}
+
+func (s *Store) GetMediaByIDs(ctx context.Context, ids []pgtype.UUID) ([]database.Medium, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var media []database.Medium
+	for _, id := range ids {
+		if medium, ok := s.media[id]; ok {
+			media = append(media, medium)
+		}
+	}
+	return media, nil
+}
+
+func (s *Store) AttachMedia(ctx context.Context, arg database.AttachMediaParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.chirps[arg.ChirpID]; !ok {
+		return 0, foreignKeyViolation("media", "media_chirp_id_fkey")
+	}
+
+	var attached int64
+	attachedAt := s.now()
+	for i, id := range arg.Ids {
+		medium, ok := s.media[id]
+		if !ok || medium.UserID != arg.UserID || medium.ChirpID.Valid || medium.AttachedAt.Valid {
+			continue
+		}
+		medium.ChirpID = arg.ChirpID
+		medium.AttachedAt = attachedAt
+		medium.Position = int32(i + 1)
+		if i < len(arg.AltTexts) {
+			medium.AltText = arg.AltTexts[i]
+		}
+		s.media[id] = medium
+		attached++
+	}
+	return attached, nil
+}
+
+func (s *Store) GetChirpMedia(ctx context.Context, chirpIds []pgtype.UUID) ([]database.Medium, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var media []database.Medium
+	for _, medium := range s.media {
+		if slices.Contains(chirpIds, medium.ChirpID) {
+			media = append(media, medium)
+		}
+	}
+	slices.SortFunc(media, func(a, b database.Medium) int {
+		if c := bytes.Compare(a.ChirpID.Bytes[:], b.ChirpID.Bytes[:]); c != 0 {
+			return c
+		}
+		return int(a.Position - b.Position)
+	})
+	return media, nil
+}
+
+func (s *Store) DeleteOrphanedMedia(ctx context.Context, arg database.DeleteOrphanedMediaParams) ([]database.DeleteOrphanedMediaRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.DeleteOrphanedMediaRow
+	for id, medium := range s.media {
+		if len(rows) >= int(arg.MaxResults) {
+			break
+		}
+		if medium.ChirpID.Valid {
+			continue
+		}
+		if medium.AttachedAt.Valid || !medium.UserID.Valid || medium.CreatedAt.Time.Before(arg.UnattachedBefore.Time) {
+			rows = append(rows, database.DeleteOrphanedMediaRow{BlobKey: medium.BlobKey, ThumbnailKey: medium.ThumbnailKey})
+			delete(s.media, id)
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,345 @@
+// Package memstore implements database.Querier in memory so that handlers
+// can be exercised without a running PostgreSQL server.
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Store is a thread-safe in-memory database.Querier. It mirrors the
+// constraints declared in sql/schema: unique and foreign key violations are
+// reported as *pgconn.PgError and deleting a user cascades to the rows that
+// reference it.
+type Store struct {
+	mu                    sync.Mutex
+	users                 map[pgtype.UUID]database.User
+	chirps                map[pgtype.UUID]database.Chirp
+	refreshTokens         map[string]database.RefreshToken
+	tags                  map[string]database.Tag
+	chirpTags             map[chirpTagKey]database.ChirpTag
+	follows               map[followKey]database.Follow
+	likes                 map[likeKey]database.Like
+	bookmarks             map[bookmarkKey]database.Bookmark
+	blocks                map[blockKey]database.Block
+	mutes                 map[muteKey]database.Mute
+	media                 map[pgtype.UUID]database.Medium
+	polls                 map[pgtype.UUID]database.Poll
+	pollVotes             map[pollVoteKey]database.PollVote
+	scheduledChirps       map[pgtype.UUID]database.ScheduledChirp
+	drafts                map[pgtype.UUID]database.Draft
+	lists                 map[pgtype.UUID]database.List
+	listMembers           map[listMemberKey]database.ListMember
+	conversations         map[pgtype.UUID]database.Conversation
+	participants          map[participantKey]database.ConversationParticipant
+	messages              map[pgtype.UUID]database.Message
+	notifications         map[notificationKey]database.Notification
+	disabledNotifications map[notificationTypeKey]database.DisabledNotification
+	fileserverHits        int64
+	lastNow               time.Time
+}
+
+var _ database.Querier = (*Store)(nil)
+
+// New returns an empty Store.
+func New() *Store {
+	return &Store{
+		users:                 make(map[pgtype.UUID]database.User),
+		chirps:                make(map[pgtype.UUID]database.Chirp),
+		refreshTokens:         make(map[string]database.RefreshToken),
+		tags:                  make(map[string]database.Tag),
+		chirpTags:             make(map[chirpTagKey]database.ChirpTag),
+		follows:               make(map[followKey]database.Follow),
+		likes:                 make(map[likeKey]database.Like),
+		bookmarks:             make(map[bookmarkKey]database.Bookmark),
+		blocks:                make(map[blockKey]database.Block),
+		mutes:                 make(map[muteKey]database.Mute),
+		media:                 make(map[pgtype.UUID]database.Medium),
+		polls:                 make(map[pgtype.UUID]database.Poll),
+		pollVotes:             make(map[pollVoteKey]database.PollVote),
+		scheduledChirps:       make(map[pgtype.UUID]database.ScheduledChirp),
+		drafts:                make(map[pgtype.UUID]database.Draft),
+		lists:                 make(map[pgtype.UUID]database.List),
+		listMembers:           make(map[listMemberKey]database.ListMember),
+		conversations:         make(map[pgtype.UUID]database.Conversation),
+		participants:          make(map[participantKey]database.ConversationParticipant),
+		messages:              make(map[pgtype.UUID]database.Message),
+		notifications:         make(map[notificationKey]database.Notification),
+		disabledNotifications: make(map[notificationTypeKey]database.DisabledNotification),
+	}
+}
+
+// now returns the current time with the microsecond precision of a
+// PostgreSQL TIMESTAMP column. Successive calls never return the same
+// instant, so rows keep their insertion order. The caller must hold s.mu.
+func (s *Store) now() pgtype.Timestamp {
+	t := time.Now().UTC().Truncate(time.Microsecond)
+	if !t.After(s.lastNow) {
+		t = s.lastNow.Add(time.Microsecond)
+	}
+	s.lastNow = t
+	return pgtype.Timestamp{Time: t, Valid: true}
+}
+
+func newUUID() pgtype.UUID {
+	return pgtype.UUID{Bytes: uuid.New(), Valid: true}
+}
+
+func uniqueViolation(constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           pgerrcode.UniqueViolation,
+		Message:        fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		ConstraintName: constraint,
+	}
+}
+
+func foreignKeyViolation(table, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           pgerrcode.ForeignKeyViolation,
+		Message:        fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+func checkViolation(table, constraint string) error {
+	return &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           pgerrcode.CheckViolation,
+		Message:        fmt.Sprintf("new row for relation %q violates check constraint %q", table, constraint),
+		TableName:      table,
+		ConstraintName: constraint,
+	}
+}
+
+// compareKey compares the keyset pagination keys (created_at, id) of two
+// rows, like a PostgreSQL row comparison.
+func compareKey(createdAt pgtype.Timestamp, id pgtype.UUID, otherCreatedAt pgtype.Timestamp, otherID pgtype.UUID) int {
+	if c := createdAt.Time.Compare(otherCreatedAt.Time); c != 0 {
+		return c
+	}
+	return bytes.Compare(id.Bytes[:], otherID.Bytes[:])
+}
+
+// expired reports whether an ephemeral chirp has passed its expiry time.
+// Expired chirps are invisible to every query until the reaper deletes them.
+func expired(chirp database.Chirp) bool {
+	return chirp.ExpiresAt.Valid && !chirp.ExpiresAt.Time.After(time.Now())
+}
+
+// pageChirps returns up to limit unexpired chirps matching keep that come
+// after the cursor in (created_at, id) order, or before it when desc is set.
+// The caller must hold s.mu.
+func (s *Store) pageChirps(keep func(database.Chirp) bool, cursorCreatedAt pgtype.Timestamp, cursorID pgtype.UUID, limit int32, desc bool) []database.Chirp {
+	var chirps []database.Chirp
+	for _, chirp := range s.chirps {
+		c := compareKey(chirp.CreatedAt, chirp.ID, cursorCreatedAt, cursorID)
+		if !expired(chirp) && keep(chirp) && (!desc && c > 0 || desc && c < 0) {
+			chirps = append(chirps, chirp)
+		}
+	}
+	return sortAndLimit(chirps, func(chirp database.Chirp) (pgtype.Timestamp, pgtype.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	}, limit, desc)
+}
+
+// sortAndLimit orders rows by the key returned by keyOf and keeps the first
+// limit of them.
+func sortAndLimit[T any](rows []T, keyOf func(T) (pgtype.Timestamp, pgtype.UUID), limit int32, desc bool) []T {
+	slices.SortFunc(rows, func(a, b T) int {
+		aCreatedAt, aID := keyOf(a)
+		bCreatedAt, bID := keyOf(b)
+		c := compareKey(aCreatedAt, aID, bCreatedAt, bID)
+		if desc {
+			return -c
+		}
+		return c
+	})
+	if len(rows) > int(limit) {
+		rows = rows[:limit]
+	}
+	return rows
+}
+
+func (s *Store) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return database.Chirp{}, foreignKeyViolation("chirps", "chirps_user_id_fkey")
+	}
+
+	timestamp := s.now()
+	chirp := database.Chirp{
+		ID:             newUUID(),
+		CreatedAt:      timestamp,
+		UpdatedAt:      timestamp,
+		Body:           arg.Body,
+		UserID:         arg.UserID,
+		QuoteOf:        arg.QuoteOf,
+		InReplyTo:      arg.InReplyTo,
+		ConversationID: arg.ConversationID,
+		AncestorIds:    slices.Clone(arg.AncestorIds),
+		ExpiresAt:      arg.ExpiresAt,
+	}
+	s.chirps[chirp.ID] = chirp
+	return chirp, nil
+}
+
+// deleteChirp removes a chirp together with every row referencing it, as
+// ON DELETE CASCADE does, and unlinks its media and unpins it, as ON DELETE
+// SET NULL does. The caller must hold s.mu.
+func (s *Store) deleteChirp(id pgtype.UUID) {
+	for key := range s.chirpTags {
+		if key.chirpID == id {
+			delete(s.chirpTags, key)
+		}
+	}
+	for key := range s.likes {
+		if key.chirpID == id {
+			delete(s.likes, key)
+		}
+	}
+	for key := range s.bookmarks {
+		if key.chirpID == id {
+			delete(s.bookmarks, key)
+		}
+	}
+	for key := range s.pollVotes {
+		if key.chirpID == id {
+			delete(s.pollVotes, key)
+		}
+	}
+	delete(s.polls, id)
+	for key := range s.notifications {
+		if key.chirpID == id {
+			delete(s.notifications, key)
+		}
+	}
+	for mediaID, medium := range s.media {
+		if medium.ChirpID == id {
+			medium.ChirpID = pgtype.UUID{}
+			s.media[mediaID] = medium
+		}
+	}
+	for userID, user := range s.users {
+		if user.PinnedChirpID == id {
+			user.PinnedChirpID = pgtype.UUID{}
+			s.users[userID] = user
+		}
+	}
+	delete(s.chirps, id)
+	for rechirpID, chirp := range s.chirps {
+		if chirp.RechirpOf == id {
+			s.deleteChirp(rechirpID)
+		}
+	}
+}
+
+func (s *Store) DeleteChirp(ctx context.Context, id pgtype.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleteChirp(id)
+	return nil
+}
+
+func (s *Store) DeleteExpiredChirps(ctx context.Context, maxResults int32) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for id, chirp := range s.chirps {
+		if deleted >= int64(maxResults) {
+			break
+		}
+		if expired(chirp) {
+			s.deleteChirp(id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *Store) GetChirp(ctx context.Context, id pgtype.UUID) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chirp, ok := s.chirps[id]
+	if !ok || expired(chirp) {
+		return database.Chirp{}, pgx.ErrNoRows
+	}
+	return chirp, nil
+}
+
+func (s *Store) GetChirps(ctx context.Context, arg database.GetChirpsParams) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	visible := func(chirp database.Chirp) bool { return !s.hidden(arg.ViewerID, chirp.UserID) }
+	return s.pageChirps(visible, arg.CursorCreatedAt, arg.CursorID, arg.PageSize, false), nil
+}
+
+func (s *Store) GetChirpsDesc(ctx context.Context, arg database.GetChirpsDescParams) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	visible := func(chirp database.Chirp) bool { return !s.hidden(arg.ViewerID, chirp.UserID) }
+	return s.pageChirps(visible, arg.CursorCreatedAt, arg.CursorID, arg.PageSize, true), nil
+}
+
+func (s *Store) GetChirpsFromAuthor(ctx context.Context, arg database.GetChirpsFromAuthorParams) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pinned := s.users[arg.UserID].PinnedChirpID
+	byAuthor := func(chirp database.Chirp) bool {
+		return chirp.UserID == arg.UserID && chirp.ID != pinned && !s.hidden(arg.ViewerID, chirp.UserID)
+	}
+	return s.pageChirps(byAuthor, arg.CursorCreatedAt, arg.CursorID, arg.PageSize, false), nil
+}
+
+func (s *Store) GetChirpsFromAuthorDesc(ctx context.Context, arg database.GetChirpsFromAuthorDescParams) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pinned := s.users[arg.UserID].PinnedChirpID
+	byAuthor := func(chirp database.Chirp) bool {
+		return chirp.UserID == arg.UserID && chirp.ID != pinned && !s.hidden(arg.ViewerID, chirp.UserID)
+	}
+	return s.pageChirps(byAuthor, arg.CursorCreatedAt, arg.CursorID, arg.PageSize, true), nil
+}
+
+func (s *Store) GetTimeline(ctx context.Context, arg database.GetTimelineParams) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inTimeline := func(chirp database.Chirp) bool {
+		_, following := s.follows[followKey{followerID: arg.UserID, followeeID: chirp.UserID}]
+		return chirp.UserID == arg.UserID || following && !s.hidden(arg.UserID, chirp.UserID)
+	}
+	return s.pageChirps(inTimeline, arg.CursorCreatedAt, arg.CursorID, arg.PageSize, true), nil
+}
+
+func (s *Store) RemoveAllChirps(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.chirps {
+		s.deleteChirp(id)
+	}
+	return nil
+}
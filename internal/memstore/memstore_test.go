@@ -0,0 +1,159 @@
+package memstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateUser(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	t.Run("Unique Email", func(t *testing.T) {
+		_, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "x"})
+		require.NoError(t, err)
+
+		_, err = s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "y"})
+		var pgErr *pgconn.PgError
+		require.True(t, errors.As(err, &pgErr))
+		assert.Equal(t, pgerrcode.UniqueViolation, pgErr.Code)
+	})
+
+	t.Run("Update To Taken Email", func(t *testing.T) {
+		user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "b@example.com", HashedPassword: "x"})
+		require.NoError(t, err)
+
+		_, err = s.UpdateUser(ctx, database.UpdateUserParams{ID: user.ID, Email: "a@example.com"})
+		var pgErr *pgconn.PgError
+		require.True(t, errors.As(err, &pgErr))
+		assert.Equal(t, pgerrcode.UniqueViolation, pgErr.Code)
+	})
+}
+
+func TestCreateChirp(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	t.Run("Unknown Author", func(t *testing.T) {
+		_, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "hello", UserID: newUUID()})
+		var pgErr *pgconn.PgError
+		require.True(t, errors.As(err, &pgErr))
+		assert.Equal(t, pgerrcode.ForeignKeyViolation, pgErr.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		_, err := s.GetChirp(ctx, newUUID())
+		assert.ErrorIs(t, err, pgx.ErrNoRows)
+	})
+}
+
+func TestRemoveAllUsersCascades(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	user, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "x"})
+	require.NoError(t, err)
+	chirp, err := s.CreateChirp(ctx, database.CreateChirpParams{Body: "hello", UserID: user.ID})
+	require.NoError(t, err)
+	_, err = s.CreateRefreshToken(ctx, database.CreateRefreshTokenParams{Token: "token", UserID: user.ID})
+	require.NoError(t, err)
+	other, err := s.CreateUser(ctx, database.CreateUserParams{Email: "b@example.com", HashedPassword: "x"})
+	require.NoError(t, err)
+	_, err = s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: other.ID, FolloweeID: user.ID})
+	require.NoError(t, err)
+	_, err = s.CreateLike(ctx, database.CreateLikeParams{UserID: other.ID, ChirpID: chirp.ID})
+	require.NoError(t, err)
+	conversation, err := s.CreateConversation(ctx, database.CreateConversationParams{ParticipantIds: []pgtype.UUID{user.ID, other.ID}})
+	require.NoError(t, err)
+	_, err = s.CreateMessage(ctx, database.CreateMessageParams{Body: "hi", ConversationID: conversation.ID, UserID: user.ID})
+	require.NoError(t, err)
+
+	require.NoError(t, s.RemoveAllUsers(ctx))
+
+	_, err = s.GetChirp(ctx, chirp.ID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	_, err = s.GetRefreshToken(ctx, "token")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	assert.Empty(t, s.follows)
+	assert.Empty(t, s.likes)
+	assert.Empty(t, s.participants)
+	assert.Empty(t, s.messages)
+}
+
+func TestCreateFollow(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	a, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "x"})
+	require.NoError(t, err)
+	b, err := s.CreateUser(ctx, database.CreateUserParams{Email: "b@example.com", HashedPassword: "x"})
+	require.NoError(t, err)
+
+	t.Run("Duplicate", func(t *testing.T) {
+		n, err := s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: a.ID, FolloweeID: b.ID})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, n)
+
+		n, err = s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: a.ID, FolloweeID: b.ID})
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, n)
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		_, err := s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: a.ID, FolloweeID: a.ID})
+		var pgErr *pgconn.PgError
+		require.True(t, errors.As(err, &pgErr))
+		assert.Equal(t, pgerrcode.CheckViolation, pgErr.Code)
+	})
+}
+
+func TestCreateBlock(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	a, err := s.CreateUser(ctx, database.CreateUserParams{Email: "a@example.com", HashedPassword: "x"})
+	require.NoError(t, err)
+	b, err := s.CreateUser(ctx, database.CreateUserParams{Email: "b@example.com", HashedPassword: "x"})
+	require.NoError(t, err)
+
+	t.Run("Removes Follows", func(t *testing.T) {
+		_, err := s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: a.ID, FolloweeID: b.ID})
+		require.NoError(t, err)
+		_, err = s.CreateFollow(ctx, database.CreateFollowParams{FollowerID: b.ID, FolloweeID: a.ID})
+		require.NoError(t, err)
+
+		n, err := s.CreateBlock(ctx, database.CreateBlockParams{BlockerID: a.ID, BlockedID: b.ID})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, n)
+		assert.Empty(t, s.follows)
+
+		blocked, err := s.IsBlocked(ctx, database.IsBlockedParams{UserID: b.ID, OtherID: a.ID})
+		require.NoError(t, err)
+		assert.True(t, blocked)
+	})
+
+	t.Run("Self", func(t *testing.T) {
+		_, err := s.CreateBlock(ctx, database.CreateBlockParams{BlockerID: a.ID, BlockedID: a.ID})
+		var pgErr *pgconn.PgError
+		require.True(t, errors.As(err, &pgErr))
+		assert.Equal(t, pgerrcode.CheckViolation, pgErr.Code)
+	})
+
+	t.Run("Cascade", func(t *testing.T) {
+		_, err := s.CreateMute(ctx, database.CreateMuteParams{MuterID: b.ID, MutedID: a.ID})
+		require.NoError(t, err)
+
+		require.NoError(t, s.RemoveAllUsers(ctx))
+		assert.Empty(t, s.blocks)
+		assert.Empty(t, s.mutes)
+	})
+}
@@ -0,0 +1,28 @@
+package memstore
+
+import (
+	"context"
+)
+
+func (s *Store) AddFileserverHits(ctx context.Context, hits int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fileserverHits += hits
+	return nil
+}
+
+func (s *Store) GetFileserverHits(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.fileserverHits, nil
+}
+
+func (s *Store) ResetFileserverHits(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fileserverHits = 0
+	return nil
+}
@@ -0,0 +1,189 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// notificationKey mirrors the notifications_event_key unique constraint.
+type notificationKey struct {
+	userID  pgtype.UUID
+	kind    string
+	chirpID pgtype.UUID
+	actorID pgtype.UUID
+}
+
+type notificationTypeKey struct {
+	userID pgtype.UUID
+	kind   string
+}
+
+func (s *Store) CreateNotifications(ctx context.Context, arg database.CreateNotificationsParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.ActorID]; !ok {
+		return 0, foreignKeyViolation("notifications", "notifications_actor_id_fkey")
+	}
+	if _, ok := s.chirps[arg.ChirpID]; arg.ChirpID.Valid && !ok {
+		return 0, foreignKeyViolation("notifications", "notifications_chirp_id_fkey")
+	}
+
+	var created int64
+	for _, userID := range arg.UserIds {
+		if userID == arg.ActorID || s.hidden(userID, arg.ActorID) {
+			continue
+		}
+		if _, disabled := s.disabledNotifications[notificationTypeKey{userID: userID, kind: arg.Type}]; disabled {
+			continue
+		}
+		if _, ok := s.users[userID]; !ok {
+			return 0, foreignKeyViolation("notifications", "notifications_user_id_fkey")
+		}
+
+		key := notificationKey{userID: userID, kind: arg.Type, chirpID: arg.ChirpID, actorID: arg.ActorID}
+		if _, ok := s.notifications[key]; ok {
+			continue
+		}
+		s.notifications[key] = database.Notification{
+			ID:        newUUID(),
+			CreatedAt: s.now(),
+			UserID:    userID,
+			ActorID:   arg.ActorID,
+			Type:      arg.Type,
+			ChirpID:   arg.ChirpID,
+		}
+		created++
+	}
+	return created, nil
+}
+
+func (s *Store) DeleteNotification(ctx context.Context, arg database.DeleteNotificationParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.notifications, notificationKey{userID: arg.UserID, kind: arg.Type, chirpID: arg.ChirpID, actorID: arg.ActorID})
+	return nil
+}
+
+func (s *Store) GetNotificationGroups(ctx context.Context, arg database.GetNotificationGroupsParams) ([]database.GetNotificationGroupsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type groupKey struct {
+		kind    string
+		chirpID pgtype.UUID
+	}
+	grouped := make(map[groupKey][]database.Notification)
+	for key, notification := range s.notifications {
+		if key.userID == arg.UserID && !s.hidden(arg.UserID, key.actorID) {
+			group := groupKey{kind: key.kind, chirpID: key.chirpID}
+			grouped[group] = append(grouped[group], notification)
+		}
+	}
+
+	var rows []database.GetNotificationGroupsRow
+	for group, notifications := range grouped {
+		notifications = sortAndLimit(notifications, func(notification database.Notification) (pgtype.Timestamp, pgtype.UUID) {
+			return notification.CreatedAt, notification.ID
+		}, int32(len(notifications)), true)
+
+		row := database.GetNotificationGroupsRow{
+			Type:     group.kind,
+			ChirpID:  group.chirpID,
+			Count:    int64(len(notifications)),
+			LatestAt: notifications[0].CreatedAt,
+			LatestID: notifications[0].ID,
+		}
+		for i, notification := range notifications {
+			if !notification.ReadAt.Valid {
+				row.UnreadCount++
+			}
+			if i < 3 {
+				row.ActorIds = append(row.ActorIds, notification.ActorID)
+			}
+		}
+		if compareKey(row.LatestAt, row.LatestID, arg.CursorLatestAt, arg.CursorID) < 0 {
+			rows = append(rows, row)
+		}
+	}
+	return sortAndLimit(rows, func(row database.GetNotificationGroupsRow) (pgtype.Timestamp, pgtype.UUID) {
+		return row.LatestAt, row.LatestID
+	}, arg.PageSize, true), nil
+}
+
+func (s *Store) MarkNotificationsRead(ctx context.Context, arg database.MarkNotificationsReadParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var marked int64
+	readAt := s.now()
+	for key, notification := range s.notifications {
+		if key.userID != arg.UserID || notification.ReadAt.Valid {
+			continue
+		}
+		if arg.Type.Valid && (key.kind != arg.Type.String || key.chirpID != arg.ChirpID) {
+			continue
+		}
+		notification.ReadAt = readAt
+		s.notifications[key] = notification
+		marked++
+	}
+	return marked, nil
+}
+
+func (s *Store) CountUnreadNotifications(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type groupKey struct {
+		kind    string
+		chirpID pgtype.UUID
+	}
+	unread := make(map[groupKey]bool)
+	for key, notification := range s.notifications {
+		if key.userID == userID && !notification.ReadAt.Valid && !s.hidden(userID, key.actorID) {
+			unread[groupKey{kind: key.kind, chirpID: key.chirpID}] = true
+		}
+	}
+	return int64(len(unread)), nil
+}
+
+func (s *Store) GetDisabledNotificationTypes(ctx context.Context, userID pgtype.UUID) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kinds []string
+	for key := range s.disabledNotifications {
+		if key.userID == userID {
+			kinds = append(kinds, key.kind)
+		}
+	}
+	slices.Sort(kinds)
+	return kinds, nil
+}
+
+func (s *Store) DisableNotificationType(ctx context.Context, arg database.DisableNotificationTypeParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return foreignKeyViolation("disabled_notifications", "disabled_notifications_user_id_fkey")
+	}
+	s.disabledNotifications[notificationTypeKey{userID: arg.UserID, kind: arg.Type}] = database.DisabledNotification{
+		UserID: arg.UserID,
+		Type:   arg.Type,
+	}
+	return nil
+}
+
+func (s *Store) EnableNotificationType(ctx context.Context, arg database.EnableNotificationTypeParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.disabledNotifications, notificationTypeKey{userID: arg.UserID, kind: arg.Type})
+	return nil
+}
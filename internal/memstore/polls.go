@@ -0,0 +1,114 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type pollVoteKey struct {
+	chirpID pgtype.UUID
+	userID  pgtype.UUID
+}
+
+func (s *Store) CreatePoll(ctx context.Context, arg database.CreatePollParams) (database.Poll, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.chirps[arg.ChirpID]; !ok {
+		return database.Poll{}, foreignKeyViolation("polls", "polls_chirp_id_fkey")
+	}
+	if _, ok := s.polls[arg.ChirpID]; ok {
+		return database.Poll{}, uniqueViolation("polls_pkey")
+	}
+	if len(arg.Options) < 2 || len(arg.Options) > 4 {
+		return database.Poll{}, checkViolation("polls", "polls_option_count")
+	}
+
+	poll := database.Poll{ChirpID: arg.ChirpID, Options: slices.Clone(arg.Options), ClosesAt: arg.ClosesAt}
+	s.polls[poll.ChirpID] = poll
+	return poll, nil
+}
+
+func (s *Store) GetPoll(ctx context.Context, chirpID pgtype.UUID) (database.Poll, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	poll, ok := s.polls[chirpID]
+	if !ok {
+		return database.Poll{}, pgx.ErrNoRows
+	}
+	return poll, nil
+}
+
+func (s *Store) GetPolls(ctx context.Context, chirpIds []pgtype.UUID) ([]database.Poll, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var polls []database.Poll
+	for _, id := range chirpIds {
+		if poll, ok := s.polls[id]; ok {
+			polls = append(polls, poll)
+		}
+	}
+	return polls, nil
+}
+
+func (s *Store) CreatePollVote(ctx context.Context, arg database.CreatePollVoteParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	poll, ok := s.polls[arg.ChirpID]
+	if !ok || !poll.ClosesAt.Time.After(time.Now()) || arg.OptionIndex < 0 || int(arg.OptionIndex) >= len(poll.Options) {
+		return 0, nil
+	}
+	if _, ok := s.users[arg.UserID]; !ok {
+		return 0, foreignKeyViolation("poll_votes", "poll_votes_user_id_fkey")
+	}
+
+	key := pollVoteKey{chirpID: arg.ChirpID, userID: arg.UserID}
+	if _, ok := s.pollVotes[key]; ok {
+		return 0, nil
+	}
+	s.pollVotes[key] = database.PollVote{ChirpID: arg.ChirpID, UserID: arg.UserID, OptionIndex: arg.OptionIndex, CreatedAt: s.now()}
+	return 1, nil
+}
+
+func (s *Store) GetPollVoteCounts(ctx context.Context, chirpIds []pgtype.UUID) ([]database.GetPollVoteCountsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type countKey struct {
+		chirpID     pgtype.UUID
+		optionIndex int32
+	}
+	counts := make(map[countKey]int64)
+	for key, vote := range s.pollVotes {
+		if slices.Contains(chirpIds, key.chirpID) {
+			counts[countKey{chirpID: key.chirpID, optionIndex: vote.OptionIndex}]++
+		}
+	}
+
+	var rows []database.GetPollVoteCountsRow
+	for key, count := range counts {
+		rows = append(rows, database.GetPollVoteCountsRow{ChirpID: key.chirpID, OptionIndex: key.optionIndex, VoteCount: count})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetPollVotes(ctx context.Context, arg database.GetPollVotesParams) ([]database.PollVote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var votes []database.PollVote
+	for key, vote := range s.pollVotes {
+		if key.userID == arg.UserID && slices.Contains(arg.ChirpIds, key.chirpID) {
+			votes = append(votes, vote)
+		}
+	}
+	return votes, nil
+}
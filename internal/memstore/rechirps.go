@@ -0,0 +1,114 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// findRechirp returns the rechirp of rechirpOf by userID. The caller must
+// hold s.mu.
+func (s *Store) findRechirp(userID, rechirpOf pgtype.UUID) (database.Chirp, bool) {
+	for _, chirp := range s.chirps {
+		if chirp.UserID == userID && chirp.RechirpOf == rechirpOf {
+			return chirp, true
+		}
+	}
+	return database.Chirp{}, false
+}
+
+func (s *Store) CreateRechirp(ctx context.Context, arg database.CreateRechirpParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return database.Chirp{}, foreignKeyViolation("chirps", "chirps_user_id_fkey")
+	}
+	if _, ok := s.chirps[arg.RechirpOf]; !ok {
+		return database.Chirp{}, foreignKeyViolation("chirps", "chirps_rechirp_of_fkey")
+	}
+	if _, ok := s.findRechirp(arg.UserID, arg.RechirpOf); ok {
+		return database.Chirp{}, pgx.ErrNoRows
+	}
+
+	timestamp := s.now()
+	chirp := database.Chirp{
+		ID:        newUUID(),
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+		UserID:    arg.UserID,
+		RechirpOf: arg.RechirpOf,
+	}
+	s.chirps[chirp.ID] = chirp
+	return chirp, nil
+}
+
+func (s *Store) GetRechirp(ctx context.Context, arg database.GetRechirpParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chirp, ok := s.findRechirp(arg.UserID, arg.RechirpOf)
+	if !ok {
+		return database.Chirp{}, pgx.ErrNoRows
+	}
+	return chirp, nil
+}
+
+func (s *Store) DeleteRechirp(ctx context.Context, arg database.DeleteRechirpParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chirp, ok := s.findRechirp(arg.UserID, arg.RechirpOf)
+	if !ok {
+		return 0, nil
+	}
+	s.deleteChirp(chirp.ID)
+	return 1, nil
+}
+
+func (s *Store) GetChirpsByIDs(ctx context.Context, ids []pgtype.UUID) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var chirps []database.Chirp
+	for _, chirp := range s.chirps {
+		if slices.Contains(ids, chirp.ID) && !expired(chirp) {
+			chirps = append(chirps, chirp)
+		}
+	}
+	return chirps, nil
+}
+
+func (s *Store) GetRechirpCounts(ctx context.Context, chirpIds []pgtype.UUID) ([]database.GetRechirpCountsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[pgtype.UUID]int64)
+	for _, chirp := range s.chirps {
+		if chirp.RechirpOf.Valid && slices.Contains(chirpIds, chirp.RechirpOf) {
+			counts[chirp.RechirpOf]++
+		}
+	}
+
+	var rows []database.GetRechirpCountsRow
+	for chirpID, count := range counts {
+		rows = append(rows, database.GetRechirpCountsRow{ChirpID: chirpID, RechirpCount: count})
+	}
+	return rows, nil
+}
+
+func (s *Store) GetRechirpedChirpIDs(ctx context.Context, arg database.GetRechirpedChirpIDsParams) ([]pgtype.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []pgtype.UUID
+	for _, chirp := range s.chirps {
+		if chirp.UserID == arg.UserID && chirp.RechirpOf.Valid && slices.Contains(arg.ChirpIds, chirp.RechirpOf) {
+			ids = append(ids, chirp.RechirpOf)
+		}
+	}
+	return ids, nil
+}
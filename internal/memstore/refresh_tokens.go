@@ -0,0 +1,62 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// refreshTokenTTL matches the INTERVAL used by CreateRefreshToken.
+const refreshTokenTTL = 60 * 24 * time.Hour
+
+func (s *Store) CreateRefreshToken(ctx context.Context, arg database.CreateRefreshTokenParams) (database.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.refreshTokens[arg.Token]; ok {
+		return database.RefreshToken{}, uniqueViolation("refresh_tokens_pkey")
+	}
+	if _, ok := s.users[arg.UserID]; !ok {
+		return database.RefreshToken{}, foreignKeyViolation("refresh_tokens", "refresh_tokens_user_id_fkey")
+	}
+
+	timestamp := s.now()
+	refreshToken := database.RefreshToken{
+		Token:     arg.Token,
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+		UserID:    arg.UserID,
+		ExpiresAt: pgtype.Timestamp{Time: timestamp.Time.Add(refreshTokenTTL), Valid: true},
+	}
+	s.refreshTokens[refreshToken.Token] = refreshToken
+	return refreshToken, nil
+}
+
+func (s *Store) GetRefreshToken(ctx context.Context, token string) (database.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refreshToken, ok := s.refreshTokens[token]
+	if !ok {
+		return database.RefreshToken{}, pgx.ErrNoRows
+	}
+	return refreshToken, nil
+}
+
+func (s *Store) RevokeRefreshToken(ctx context.Context, arg database.RevokeRefreshTokenParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refreshToken, ok := s.refreshTokens[arg.Token]
+	if !ok {
+		return nil
+	}
+
+	refreshToken.RevokedAt = arg.RevokedAt
+	refreshToken.UpdatedAt = arg.UpdatedAt
+	s.refreshTokens[refreshToken.Token] = refreshToken
+	return nil
+}
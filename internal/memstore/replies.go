@@ -0,0 +1,37 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (s *Store) GetThreadDescendants(ctx context.Context, arg database.GetThreadDescendantsParams) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inThread := func(chirp database.Chirp) bool {
+		return slices.Contains(chirp.AncestorIds, arg.ChirpID) && !s.hidden(arg.ViewerID, chirp.UserID)
+	}
+	return s.pageChirps(inThread, arg.CursorCreatedAt, arg.CursorID, arg.PageSize, false), nil
+}
+
+func (s *Store) GetReplyCounts(ctx context.Context, chirpIds []pgtype.UUID) ([]database.GetReplyCountsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[pgtype.UUID]int64)
+	for _, chirp := range s.chirps {
+		if chirp.InReplyTo.Valid && slices.Contains(chirpIds, chirp.InReplyTo) && !expired(chirp) {
+			counts[chirp.InReplyTo]++
+		}
+	}
+
+	var rows []database.GetReplyCountsRow
+	for chirpID, count := range counts {
+		rows = append(rows, database.GetReplyCountsRow{ChirpID: chirpID, ReplyCount: count})
+	}
+	return rows, nil
+}
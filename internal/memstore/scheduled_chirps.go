@@ -0,0 +1,116 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (s *Store) CreateScheduledChirp(ctx context.Context, arg database.CreateScheduledChirpParams) (database.ScheduledChirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return database.ScheduledChirp{}, foreignKeyViolation("scheduled_chirps", "scheduled_chirps_user_id_fkey")
+	}
+
+	timestamp := s.now()
+	scheduled := database.ScheduledChirp{
+		ID:        newUUID(),
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+		Body:      arg.Body,
+		UserID:    arg.UserID,
+		PublishAt: arg.PublishAt,
+	}
+	s.scheduledChirps[scheduled.ID] = scheduled
+	return scheduled, nil
+}
+
+func (s *Store) GetScheduledChirp(ctx context.Context, arg database.GetScheduledChirpParams) (database.ScheduledChirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scheduled, ok := s.scheduledChirps[arg.ID]
+	if !ok || scheduled.UserID != arg.UserID {
+		return database.ScheduledChirp{}, pgx.ErrNoRows
+	}
+	return scheduled, nil
+}
+
+func (s *Store) GetScheduledChirps(ctx context.Context, arg database.GetScheduledChirpsParams) ([]database.ScheduledChirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var scheduled []database.ScheduledChirp
+	for _, chirp := range s.scheduledChirps {
+		if chirp.UserID == arg.UserID && compareKey(chirp.PublishAt, chirp.ID, arg.CursorPublishAt, arg.CursorID) > 0 {
+			scheduled = append(scheduled, chirp)
+		}
+	}
+	return sortAndLimit(scheduled, func(chirp database.ScheduledChirp) (pgtype.Timestamp, pgtype.UUID) {
+		return chirp.PublishAt, chirp.ID
+	}, arg.PageSize, false), nil
+}
+
+func (s *Store) UpdateScheduledChirp(ctx context.Context, arg database.UpdateScheduledChirpParams) (database.ScheduledChirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scheduled, ok := s.scheduledChirps[arg.ID]
+	if !ok || scheduled.UserID != arg.UserID {
+		return database.ScheduledChirp{}, pgx.ErrNoRows
+	}
+	scheduled.Body = arg.Body
+	scheduled.PublishAt = arg.PublishAt
+	scheduled.UpdatedAt = s.now()
+	s.scheduledChirps[arg.ID] = scheduled
+	return scheduled, nil
+}
+
+func (s *Store) DeleteScheduledChirp(ctx context.Context, arg database.DeleteScheduledChirpParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scheduled, ok := s.scheduledChirps[arg.ID]
+	if !ok || scheduled.UserID != arg.UserID {
+		return 0, nil
+	}
+	delete(s.scheduledChirps, arg.ID)
+	return 1, nil
+}
+
+func (s *Store) PublishDueChirps(ctx context.Context, maxResults int32) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []database.ScheduledChirp
+	now := time.Now()
+	for _, scheduled := range s.scheduledChirps {
+		if !scheduled.PublishAt.Time.After(now) {
+			due = append(due, scheduled)
+		}
+	}
+	due = sortAndLimit(due, func(scheduled database.ScheduledChirp) (pgtype.Timestamp, pgtype.UUID) {
+		return scheduled.PublishAt, scheduled.ID
+	}, maxResults, false)
+
+	var published []database.Chirp
+	for _, scheduled := range due {
+		timestamp := s.now()
+		chirp := database.Chirp{
+			ID:        scheduled.ID,
+			CreatedAt: timestamp,
+			UpdatedAt: timestamp,
+			Body:      scheduled.Body,
+			UserID:    scheduled.UserID,
+		}
+		s.chirps[chirp.ID] = chirp
+		delete(s.scheduledChirps, scheduled.ID)
+		published = append(published, chirp)
+	}
+	return published, nil
+}
@@ -0,0 +1,203 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The search queries receive a to_tsquery expression. The store understands
+// the subset produced by the handlers: terms joined by " & ", where a term is
+// a word, a prefix "word:*", a negation "!word" or a phrase "(a <-> b)". There
+// is no stemming or stop word removal, and rank is the number of matched
+// occurrences rather than ts_rank.
+
+type searchTerm struct {
+	words  []string
+	prefix bool
+	negate bool
+}
+
+type token struct {
+	word       string
+	start, end int
+}
+
+func parseTSQuery(query string) []searchTerm {
+	var terms []searchTerm
+	for _, raw := range strings.Split(query, " & ") {
+		term := searchTerm{}
+		if strings.HasPrefix(raw, "!") {
+			term.negate = true
+			raw = raw[1:]
+		}
+		if strings.HasSuffix(raw, ":*") {
+			term.prefix = true
+			raw = strings.TrimSuffix(raw, ":*")
+		}
+		raw = strings.Trim(raw, "()")
+		term.words = strings.Split(raw, " <-> ")
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+func tokenize(body string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range body + " " {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			tokens = append(tokens, token{word: strings.ToLower(body[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	return tokens
+}
+
+// matches returns the indexes of the tokens matched by the term.
+func (t searchTerm) matches(tokens []token) []int {
+	var matched []int
+	for i := 0; i+len(t.words) <= len(tokens); i++ {
+		ok := true
+		for j, word := range t.words {
+			candidate := tokens[i+j].word
+			if t.prefix && !strings.HasPrefix(candidate, word) || !t.prefix && candidate != word {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			for j := range t.words {
+				matched = append(matched, i+j)
+			}
+		}
+	}
+	return matched
+}
+
+// htmlEscaper escapes snippets like the html_escape SQL function.
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// search evaluates the query against a chirp body, returning whether it
+// matches, its rank and a snippet with the matches highlighted.
+func search(terms []searchTerm, body string) (bool, float32, string) {
+	tokens := tokenize(body)
+	highlighted := make(map[int]bool)
+	var rank float32
+
+	for _, term := range terms {
+		matched := term.matches(tokens)
+		if term.negate {
+			if len(matched) > 0 {
+				return false, 0, ""
+			}
+			continue
+		}
+		if len(matched) == 0 {
+			return false, 0, ""
+		}
+		rank += float32(len(matched) / len(term.words))
+		for _, i := range matched {
+			highlighted[i] = true
+		}
+	}
+
+	var snippet strings.Builder
+	last := 0
+	for i, tok := range tokens {
+		if !highlighted[i] {
+			continue
+		}
+		snippet.WriteString(htmlEscaper.Replace(body[last:tok.start]))
+		snippet.WriteString("<mark>" + htmlEscaper.Replace(body[tok.start:tok.end]) + "</mark>")
+		last = tok.end
+	}
+	snippet.WriteString(htmlEscaper.Replace(body[last:]))
+
+	return true, rank, snippet.String()
+}
+
+type searchFilter struct {
+	query    string
+	authorID pgtype.UUID
+	viewerID pgtype.UUID
+	since    pgtype.Timestamp
+	until    pgtype.Timestamp
+}
+
+// searchChirps returns every chirp matching the filter together with its rank
+// and snippet. The caller must hold s.mu.
+func (s *Store) searchChirps(filter searchFilter) []database.SearchChirpsByRankRow {
+	terms := parseTSQuery(filter.query)
+
+	var rows []database.SearchChirpsByRankRow
+	for _, chirp := range s.chirps {
+		if filter.authorID.Valid && chirp.UserID != filter.authorID {
+			continue
+		}
+		if s.hidden(filter.viewerID, chirp.UserID) || expired(chirp) {
+			continue
+		}
+		if filter.since.Valid && chirp.CreatedAt.Time.Before(filter.since.Time) {
+			continue
+		}
+		if filter.until.Valid && !chirp.CreatedAt.Time.Before(filter.until.Time) {
+			continue
+		}
+
+		ok, rank, snippet := search(terms, chirp.Body)
+		if ok {
+			rows = append(rows, database.SearchChirpsByRankRow{Chirp: chirp, Rank: rank, Snippet: snippet})
+		}
+	}
+	return rows
+}
+
+func (s *Store) SearchChirps(ctx context.Context, arg database.SearchChirpsParams) ([]database.SearchChirpsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.SearchChirpsRow
+	for _, row := range s.searchChirps(searchFilter{arg.Query, arg.AuthorID, arg.ViewerID, arg.Since, arg.Until}) {
+		if compareKey(row.Chirp.CreatedAt, row.Chirp.ID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			rows = append(rows, database.SearchChirpsRow(row))
+		}
+	}
+	return sortAndLimit(rows, func(row database.SearchChirpsRow) (pgtype.Timestamp, pgtype.UUID) {
+		return row.Chirp.CreatedAt, row.Chirp.ID
+	}, arg.PageSize, true), nil
+}
+
+func (s *Store) SearchChirpsByRank(ctx context.Context, arg database.SearchChirpsByRankParams) ([]database.SearchChirpsByRankRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []database.SearchChirpsByRankRow
+	for _, row := range s.searchChirps(searchFilter{arg.Query, arg.AuthorID, arg.ViewerID, arg.Since, arg.Until}) {
+		if row.Rank < arg.CursorRank ||
+			row.Rank == arg.CursorRank && compareKey(row.Chirp.CreatedAt, row.Chirp.ID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			rows = append(rows, row)
+		}
+	}
+
+	// Sort by key first so that the stable sort by rank breaks ties by
+	// (created_at, id).
+	rows = sortAndLimit(rows, func(row database.SearchChirpsByRankRow) (pgtype.Timestamp, pgtype.UUID) {
+		return row.Chirp.CreatedAt, row.Chirp.ID
+	}, int32(len(rows)), true)
+	slices.SortStableFunc(rows, func(a, b database.SearchChirpsByRankRow) int {
+		return cmp.Compare(b.Rank, a.Rank)
+	})
+	if len(rows) > int(arg.PageSize) {
+		rows = rows[:arg.PageSize]
+	}
+	return rows, nil
+}
@@ -0,0 +1,101 @@
+package memstore
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type chirpTagKey struct {
+	chirpID pgtype.UUID
+	tagID   pgtype.UUID
+}
+
+func (s *Store) CreateChirpTags(ctx context.Context, arg database.CreateChirpTagsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.chirps[arg.ChirpID]; !ok {
+		return foreignKeyViolation("chirp_tags", "chirp_tags_chirp_id_fkey")
+	}
+
+	for _, name := range arg.Names {
+		tag, ok := s.tags[name]
+		if !ok {
+			tag = database.Tag{ID: newUUID(), CreatedAt: s.now(), Name: name}
+			s.tags[name] = tag
+		}
+
+		key := chirpTagKey{chirpID: arg.ChirpID, tagID: tag.ID}
+		if _, ok := s.chirpTags[key]; !ok {
+			s.chirpTags[key] = database.ChirpTag{ChirpID: arg.ChirpID, TagID: tag.ID, CreatedAt: arg.CreatedAt}
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetChirpsByTag(ctx context.Context, arg database.GetChirpsByTagParams) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag, ok := s.tags[arg.Name]
+	if !ok {
+		return nil, nil
+	}
+
+	var links []database.ChirpTag
+	for _, link := range s.chirpTags {
+		chirp := s.chirps[link.ChirpID]
+		if link.TagID == tag.ID && !s.hidden(arg.ViewerID, chirp.UserID) && !expired(chirp) &&
+			compareKey(link.CreatedAt, link.ChirpID, arg.CursorCreatedAt, arg.CursorID) < 0 {
+			links = append(links, link)
+		}
+	}
+	links = sortAndLimit(links, func(link database.ChirpTag) (pgtype.Timestamp, pgtype.UUID) {
+		return link.CreatedAt, link.ChirpID
+	}, arg.PageSize, true)
+
+	var chirps []database.Chirp
+	for _, link := range links {
+		chirps = append(chirps, s.chirps[link.ChirpID])
+	}
+	return chirps, nil
+}
+
+func (s *Store) GetTagsByPrefix(ctx context.Context, arg database.GetTagsByPrefixParams) ([]database.GetTagsByPrefixRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[pgtype.UUID]int64)
+	for key := range s.chirpTags {
+		counts[key.tagID]++
+	}
+
+	var rows []database.GetTagsByPrefixRow
+	for name, tag := range s.tags {
+		if counts[tag.ID] > 0 && likePrefix(name, arg.Prefix) {
+			rows = append(rows, database.GetTagsByPrefixRow{Name: name, ChirpCount: counts[tag.ID]})
+		}
+	}
+	slices.SortFunc(rows, func(a, b database.GetTagsByPrefixRow) int {
+		if c := cmp.Compare(b.ChirpCount, a.ChirpCount); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	if len(rows) > int(arg.MaxResults) {
+		rows = rows[:arg.MaxResults]
+	}
+	return rows, nil
+}
+
+// likePrefix reports whether s matches the LIKE pattern prefix || '%', where
+// prefix may contain backslash-escaped wildcards.
+func likePrefix(s, prefix string) bool {
+	replacer := strings.NewReplacer(`\\`, `\`, `\%`, `%`, `\_`, `_`)
+	return strings.HasPrefix(s, replacer.Replace(prefix))
+}
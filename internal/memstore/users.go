@@ -0,0 +1,312 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// handleTaken reports whether a user other than id has handle, ignoring
+// case like the users_handle_key index.
+func (s *Store) handleTaken(handle pgtype.Text, id pgtype.UUID) bool {
+	if !handle.Valid {
+		return false
+	}
+	for _, user := range s.users {
+		if user.Handle.Valid && strings.EqualFold(user.Handle.String, handle.String) && user.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+// emailTaken reports whether a user other than id is registered with email.
+func (s *Store) emailTaken(email string, id pgtype.UUID) bool {
+	for _, user := range s.users {
+		if user.Email == email && user.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteUser removes a user together with every row referencing it, as
+// ON DELETE CASCADE does, and unlinks its media, as ON DELETE SET NULL does.
+// The caller must hold s.mu.
+func (s *Store) deleteUser(id pgtype.UUID) {
+	for chirpID, chirp := range s.chirps {
+		if chirp.UserID == id {
+			s.deleteChirp(chirpID)
+		}
+	}
+	for token, refreshToken := range s.refreshTokens {
+		if refreshToken.UserID == id {
+			delete(s.refreshTokens, token)
+		}
+	}
+	for key := range s.follows {
+		if key.followerID == id || key.followeeID == id {
+			delete(s.follows, key)
+		}
+	}
+	for key := range s.likes {
+		if key.userID == id {
+			delete(s.likes, key)
+		}
+	}
+	for key := range s.bookmarks {
+		if key.userID == id {
+			delete(s.bookmarks, key)
+		}
+	}
+	for key := range s.blocks {
+		if key.blockerID == id || key.blockedID == id {
+			delete(s.blocks, key)
+		}
+	}
+	for key := range s.mutes {
+		if key.muterID == id || key.mutedID == id {
+			delete(s.mutes, key)
+		}
+	}
+	for draftID, draft := range s.drafts {
+		if draft.UserID == id {
+			delete(s.drafts, draftID)
+		}
+	}
+	for scheduledID, scheduled := range s.scheduledChirps {
+		if scheduled.UserID == id {
+			delete(s.scheduledChirps, scheduledID)
+		}
+	}
+	for key := range s.pollVotes {
+		if key.userID == id {
+			delete(s.pollVotes, key)
+		}
+	}
+	for listID, list := range s.lists {
+		if list.UserID == id {
+			s.deleteList(listID)
+		}
+	}
+	for key := range s.listMembers {
+		if key.userID == id {
+			delete(s.listMembers, key)
+		}
+	}
+	for key := range s.participants {
+		if key.userID == id {
+			delete(s.participants, key)
+		}
+	}
+	for messageID, message := range s.messages {
+		if message.UserID == id {
+			delete(s.messages, messageID)
+		}
+	}
+	for key := range s.notifications {
+		if key.userID == id || key.actorID == id {
+			delete(s.notifications, key)
+		}
+	}
+	for key := range s.disabledNotifications {
+		if key.userID == id {
+			delete(s.disabledNotifications, key)
+		}
+	}
+	for mediaID, medium := range s.media {
+		if medium.UserID == id {
+			medium.UserID = pgtype.UUID{}
+			s.media[mediaID] = medium
+		}
+	}
+	delete(s.users, id)
+}
+
+func (s *Store) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.emailTaken(arg.Email, pgtype.UUID{}) {
+		return database.User{}, uniqueViolation("users_email_key")
+	}
+	if s.handleTaken(arg.Handle, pgtype.UUID{}) {
+		return database.User{}, uniqueViolation("users_handle_key")
+	}
+
+	timestamp := s.now()
+	user := database.User{
+		ID:             newUUID(),
+		CreatedAt:      timestamp,
+		UpdatedAt:      timestamp,
+		Email:          arg.Email,
+		HashedPassword: arg.HashedPassword,
+		Handle:         arg.Handle,
+	}
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return database.User{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUserByHandle(ctx context.Context, handle string) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.users {
+		if user.Handle.Valid && strings.EqualFold(user.Handle.String, handle) {
+			return user, nil
+		}
+	}
+	return database.User{}, pgx.ErrNoRows
+}
+
+func (s *Store) GetUsersByHandles(ctx context.Context, handles []string) ([]database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var users []database.User
+	for _, user := range s.users {
+		if user.Handle.Valid && slices.Contains(handles, strings.ToLower(user.Handle.String)) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (s *Store) GetUsersByIDs(ctx context.Context, ids []pgtype.UUID) ([]database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var users []database.User
+	for _, id := range ids {
+		if user, ok := s.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (s *Store) GetUserByID(ctx context.Context, id pgtype.UUID) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return database.User{}, pgx.ErrNoRows
+	}
+	return user, nil
+}
+
+func (s *Store) RemoveAllUsers(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.users {
+		s.deleteUser(id)
+	}
+	return nil
+}
+
+func (s *Store) UpdateUser(ctx context.Context, arg database.UpdateUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[arg.ID]
+	if !ok {
+		return database.User{}, pgx.ErrNoRows
+	}
+	if s.emailTaken(arg.Email, arg.ID) {
+		return database.User{}, uniqueViolation("users_email_key")
+	}
+	if s.handleTaken(arg.Handle, arg.ID) {
+		return database.User{}, uniqueViolation("users_handle_key")
+	}
+
+	user.Email = arg.Email
+	user.HashedPassword = arg.HashedPassword
+	user.Handle = arg.Handle
+	user.DisplayName = arg.DisplayName
+	user.Bio = arg.Bio
+	user.AvatarUrl = arg.AvatarUrl
+	user.UpdatedAt = arg.UpdatedAt
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *Store) UpgradeUser(ctx context.Context, arg database.UpgradeUserParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[arg.ID]
+	if !ok {
+		return nil
+	}
+
+	user.IsChirpyRed = true
+	user.UpdatedAt = arg.UpdatedAt
+	s.users[user.ID] = user
+	return nil
+}
+
+func (s *Store) PinChirp(ctx context.Context, arg database.PinChirpParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[arg.UserID]
+	if !ok {
+		return 0, nil
+	}
+	chirp, ok := s.chirps[arg.ChirpID]
+	if !ok || chirp.UserID != arg.UserID || chirp.RechirpOf.Valid || expired(chirp) {
+		return 0, nil
+	}
+
+	user.PinnedChirpID = arg.ChirpID
+	user.UpdatedAt = s.now()
+	s.users[user.ID] = user
+	return 1, nil
+}
+
+func (s *Store) UnpinChirp(ctx context.Context, id pgtype.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil
+	}
+	user.PinnedChirpID = pgtype.UUID{}
+	user.UpdatedAt = s.now()
+	s.users[user.ID] = user
+	return nil
+}
+
+func (s *Store) GetPinnedChirp(ctx context.Context, arg database.GetPinnedChirpParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[arg.UserID]
+	if !ok || !user.PinnedChirpID.Valid {
+		return database.Chirp{}, pgx.ErrNoRows
+	}
+	chirp, ok := s.chirps[user.PinnedChirpID]
+	if !ok || expired(chirp) || s.hidden(arg.ViewerID, chirp.UserID) {
+		return database.Chirp{}, pgx.ErrNoRows
+	}
+	return chirp, nil
+}
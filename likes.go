@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// isForeignKeyViolation reports whether err is caused by a row referencing
+// one that does not exist, such as liking a deleted chirp.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation
+}
+
+func (cfg *apiConfig) handleLikeChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	chirpID := pgtype.UUID{}
+	err = chirpID.Scan(r.PathValue("chirpID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(context.Background(), chirpID)
+	if err == nil {
+		err = cfg.checkNotBlocked(context.Background(), userID, chirp)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting chirp from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Liking twice is not an error, and counts are computed from the likes
+	// table, so concurrent likes cannot make them drift.
+	liked, err := cfg.db.CreateLike(context.Background(), database.CreateLikeParams{UserID: userID, ChirpID: chirpID})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error creating like: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if liked > 0 {
+		cfg.notify(context.Background(), userID, notificationLike, chirp.ID, chirp.UserID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnlikeChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	chirpID := pgtype.UUID{}
+	err = chirpID.Scan(r.PathValue("chirpID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	unliked, err := cfg.db.DeleteLike(context.Background(), database.DeleteLikeParams{UserID: userID, ChirpID: chirpID})
+	if err != nil {
+		log.Printf("Error deleting like: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if unliked > 0 {
+		// The notification goes to the author, who is only known from the
+		// chirp. Notifications of deleted chirps are already gone.
+		chirp, err := cfg.db.GetChirp(context.Background(), chirpID)
+		if err == nil {
+			cfg.retractNotification(context.Background(), chirp.UserID, userID, notificationLike, chirp.ID)
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("Error getting chirp from db: %v\n", err)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetChirpLikes(w http.ResponseWriter, r *http.Request) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	chirpID := pgtype.UUID{}
+	err = chirpID.Scan(r.PathValue("chirpID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(context.Background(), chirpID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting chirp from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// The likes of a chirp the viewer cannot see are hidden with it.
+	hidden, err := cfg.hiddenUsers(context.Background(), viewer, []pgtype.UUID{chirp.UserID})
+	if err != nil {
+		log.Printf("Error getting hidden users from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if hidden[chirp.UserID] {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "likes are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := cfg.db.GetChirpLikes(context.Background(), database.GetChirpLikesParams{
+		ChirpID:         chirpID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		ViewerID:        viewer,
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting likes from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rows, next := trimPage(rows, page, func(row database.GetChirpLikesRow) pageCursor {
+		return newPageCursor(row.LikedAt, row.User.ID)
+	})
+
+	profiles := []publicProfile{}
+	for _, row := range rows {
+		profiles = append(profiles, newPublicProfile(row.User))
+	}
+
+	resp, err := json.Marshal(profiles)
+	if err != nil {
+		log.Printf("Error marshalling profiles: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleGetUserLikes(w http.ResponseWriter, r *http.Request) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, err := cfg.lookupUser(context.Background(), r.PathValue("user"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting user from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "likes are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := cfg.db.GetUserLikes(context.Background(), database.GetUserLikesParams{
+		UserID:          user.ID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		ViewerID:        viewer,
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting liked chirps from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rows, next := trimPage(rows, page, func(row database.GetUserLikesRow) pageCursor {
+		return newPageCursor(row.LikedAt, row.Chirp.ID)
+	})
+
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = row.Chirp
+	}
+
+	responses, err := cfg.chirpResponses(context.Background(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses)
+	if err != nil {
+		log.Printf("Error marshalling chirps struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testLikedChirp struct {
+	ID        string `json:"id"`
+	LikeCount int64  `json:"like_count"`
+	LikedByMe bool   `json:"liked_by_me"`
+}
+
+func (ts *testServer) like(user testUser, chirp testChirp) {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/like", user.bearer(), nil)
+	require.Equal(ts.t, http.StatusNoContent, rec.Code, rec.Body.String())
+}
+
+func TestLikeChirp(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	chirp := ts.createChirp(alice, "hello")
+	path := "/api/chirps/" + chirp.ID
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, path+"/like", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Unknown Chirp", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps/"+bob.ID+"/like", bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Like Twice", func(t *testing.T) {
+		for range 2 {
+			rec := ts.do(http.MethodPost, path+"/like", bob.bearer(), nil)
+			require.Equal(t, http.StatusNoContent, rec.Code)
+		}
+
+		rec := ts.do(http.MethodGet, path, bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, testLikedChirp{ID: chirp.ID, LikeCount: 1, LikedByMe: true}, decode[testLikedChirp](t, rec))
+
+		rec = ts.do(http.MethodGet, path, alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, testLikedChirp{ID: chirp.ID, LikeCount: 1}, decode[testLikedChirp](t, rec))
+
+		rec = ts.do(http.MethodGet, "/api/chirps", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []testLikedChirp{{ID: chirp.ID, LikeCount: 1}}, decode[[]testLikedChirp](t, rec))
+	})
+
+	t.Run("Liked By", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, path+"/likes", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		likers := decode[[]map[string]any](t, rec)
+		require.Len(t, likers, 1)
+		assert.Equal(t, bob.ID, likers[0]["id"])
+
+		rec = ts.do(http.MethodGet, "/api/users/"+bob.ID+"/likes", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []testLikedChirp{{ID: chirp.ID, LikeCount: 1}}, decode[[]testLikedChirp](t, rec))
+	})
+
+	t.Run("Unlike", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, path+"/like", bob.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, path, bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, testLikedChirp{ID: chirp.ID}, decode[testLikedChirp](t, rec))
+	})
+
+	t.Run("Deleted Chirp", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, path+"/like", bob.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		rec = ts.do(http.MethodDelete, path, alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/users/"+bob.ID+"/likes", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, decode[[]testLikedChirp](t, rec))
+	})
+}
+
+func TestConcurrentLikes(t *testing.T) {
+	ts := newTestServer(t)
+	author := ts.signup("author@example.com")
+	chirp := ts.createChirp(author, "popular")
+
+	var fans []testUser
+	for i := range 20 {
+		fans = append(fans, ts.signup(fmt.Sprintf("fan%d@example.com", i)))
+	}
+
+	var wg sync.WaitGroup
+	for _, fan := range fans {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ts.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/like", fan.bearer(), nil)
+		}()
+	}
+	wg.Wait()
+
+	rec := ts.do(http.MethodGet, "/api/chirps/"+chirp.ID, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.EqualValues(t, len(fans), decode[testLikedChirp](t, rec).LikeCount)
+}
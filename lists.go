@@ -0,0 +1,510 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	maxListNameLength        = 25
+	maxListDescriptionLength = 100
+	// maxListMembers bounds the cost of a list timeline, which reads the
+	// newest chirps of every member.
+	maxListMembers = 500
+)
+
+var errListFull = fmt.Errorf("lists cannot have more than %d members", maxListMembers)
+
+// listParameters are the editable fields of a list. Fields left out are
+// kept as they are.
+type listParameters struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Private     *bool   `json:"private"`
+}
+
+// apply validates params and copies them into list. The error is meant for
+// the client.
+func (params listParameters) apply(list *database.List) error {
+	if params.Name != nil {
+		name := strings.TrimSpace(*params.Name)
+		if name == "" {
+			return errors.New("name cannot be empty")
+		}
+		if utf8.RuneCountInString(name) > maxListNameLength {
+			return fmt.Errorf("name cannot be longer than %d characters", maxListNameLength)
+		}
+		list.Name = name
+	}
+	if params.Description != nil {
+		if utf8.RuneCountInString(*params.Description) > maxListDescriptionLength {
+			return fmt.Errorf("description cannot be longer than %d characters", maxListDescriptionLength)
+		}
+		list.Description = *params.Description
+	}
+	if params.Private != nil {
+		list.IsPrivate = *params.Private
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handleCreateList(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := listParameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if params.Name == nil {
+		http.Error(w, "name cannot be empty", http.StatusBadRequest)
+		return
+	}
+	list := database.List{}
+	err = params.apply(&list)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err = cfg.db.CreateList(context.Background(), database.CreateListParams{
+		UserID:      userID,
+		Name:        list.Name,
+		Description: list.Description,
+		IsPrivate:   list.IsPrivate,
+	})
+	if err != nil {
+		log.Printf("Error creating list: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(list)
+	if err != nil {
+		log.Printf("Error marshalling list struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// lookupList finds the list in the request path as seen by viewer: private
+// lists of other users are not found. It writes the error response itself
+// when ok is false.
+func (cfg *apiConfig) lookupList(w http.ResponseWriter, r *http.Request, viewer pgtype.UUID) (list database.List, ok bool) {
+	id := pgtype.UUID{}
+	err := id.Scan(r.PathValue("listID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return database.List{}, false
+	}
+
+	list, err = cfg.db.GetList(context.Background(), id)
+	if err == nil && list.IsPrivate && list.UserID != viewer {
+		err = pgx.ErrNoRows
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return database.List{}, false
+		}
+		log.Printf("Error getting list from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return database.List{}, false
+	}
+	return list, true
+}
+
+// visibleList looks up the list in the request path for an optionally
+// authenticated caller. It writes the error response itself when ok is
+// false.
+func (cfg *apiConfig) visibleList(w http.ResponseWriter, r *http.Request) (viewer pgtype.UUID, list database.List, ok bool) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return viewer, list, false
+	}
+
+	list, ok = cfg.lookupList(w, r, viewer)
+	return viewer, list, ok
+}
+
+// ownList looks up the caller's list in the request path. Other users'
+// public lists are forbidden. It writes the error response itself when ok
+// is false.
+func (cfg *apiConfig) ownList(w http.ResponseWriter, r *http.Request) (list database.List, ok bool) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return database.List{}, false
+	}
+
+	list, ok = cfg.lookupList(w, r, userID)
+	if !ok {
+		return database.List{}, false
+	}
+	if list.UserID != userID {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return database.List{}, false
+	}
+	return list, true
+}
+
+func (cfg *apiConfig) handleGetList(w http.ResponseWriter, r *http.Request) {
+	_, list, ok := cfg.visibleList(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := json.Marshal(list)
+	if err != nil {
+		log.Printf("Error marshalling list struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleUpdateList(w http.ResponseWriter, r *http.Request) {
+	list, ok := cfg.ownList(w, r)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := listParameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if params.Name == nil && params.Description == nil && params.Private == nil {
+		http.Error(w, "at least one of name, description or private must be provided", http.StatusBadRequest)
+		return
+	}
+	err = params.apply(&list)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err = cfg.db.UpdateList(context.Background(), database.UpdateListParams{
+		Name:        list.Name,
+		Description: list.Description,
+		IsPrivate:   list.IsPrivate,
+		ID:          list.ID,
+		UserID:      list.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error updating list: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(list)
+	if err != nil {
+		log.Printf("Error marshalling list struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleDeleteList(w http.ResponseWriter, r *http.Request) {
+	list, ok := cfg.ownList(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.DeleteList(context.Background(), database.DeleteListParams{ID: list.ID, UserID: list.UserID})
+	if err != nil {
+		log.Printf("Error deleting list: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetUserLists(w http.ResponseWriter, r *http.Request) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, err := cfg.lookupUser(context.Background(), r.PathValue("user"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting user from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "lists are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	lists, err := cfg.db.GetUserLists(context.Background(), database.GetUserListsParams{
+		UserID:          user.ID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		ViewerID:        viewer,
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting lists from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	lists, next := trimPage(lists, page, func(list database.List) pageCursor {
+		return newPageCursor(list.CreatedAt, list.ID)
+	})
+
+	resp, err := json.Marshal(lists)
+	if err != nil {
+		log.Printf("Error marshalling lists struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleGetListMembers(w http.ResponseWriter, r *http.Request) {
+	_, list, ok := cfg.visibleList(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "list members are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := cfg.db.GetListMembers(context.Background(), database.GetListMembersParams{
+		ListID:          list.ID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting list members from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	var edges []userEdge
+	for _, row := range rows {
+		edges = append(edges, userEdge{User: row.User, CreatedAt: row.AddedAt})
+	}
+	edges, next := trimPage(edges, page, userEdgeCursor)
+
+	profiles := []publicProfile{}
+	for _, edge := range edges {
+		profiles = append(profiles, newPublicProfile(edge.User))
+	}
+
+	resp, err := json.Marshal(profiles)
+	if err != nil {
+		log.Printf("Error marshalling profiles: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+// listMemberTarget looks up the caller's list and the user named in the
+// request path. It writes the error response itself when ok is false.
+func (cfg *apiConfig) listMemberTarget(w http.ResponseWriter, r *http.Request) (list database.List, member database.User, ok bool) {
+	list, ok = cfg.ownList(w, r)
+	if !ok {
+		return list, member, false
+	}
+
+	member, err := cfg.lookupUser(context.Background(), r.PathValue("user"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return list, member, false
+		}
+		log.Printf("Error getting user from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return list, member, false
+	}
+
+	return list, member, true
+}
+
+func (cfg *apiConfig) handleAddListMember(w http.ResponseWriter, r *http.Request) {
+	list, member, ok := cfg.listMemberTarget(w, r)
+	if !ok {
+		return
+	}
+
+	blocked, err := cfg.db.IsBlocked(context.Background(), database.IsBlockedParams{UserID: list.UserID, OtherID: member.ID})
+	if err != nil {
+		log.Printf("Error checking blocks: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if blocked {
+		http.Error(w, "you cannot add this user to a list", http.StatusForbidden)
+		return
+	}
+
+	// The list is locked while it is counted so that concurrent adds cannot
+	// take it over maxListMembers.
+	err = cfg.inTx(context.Background(), func(db database.Querier) error {
+		err := db.LockList(context.Background(), list.ID)
+		if err != nil {
+			return fmt.Errorf("locking list: %w", err)
+		}
+
+		// Adding a member twice is not an error, even to a full list: the
+		// membership already exists.
+		exists, err := db.IsListMember(context.Background(), database.IsListMemberParams{ListID: list.ID, UserID: member.ID})
+		if err != nil {
+			return fmt.Errorf("checking list membership: %w", err)
+		}
+		if exists {
+			return nil
+		}
+
+		count, err := db.CountListMembers(context.Background(), list.ID)
+		if err != nil {
+			return fmt.Errorf("counting list members: %w", err)
+		}
+		if count >= maxListMembers {
+			return errListFull
+		}
+
+		_, err = db.AddListMember(context.Background(), database.AddListMemberParams{ListID: list.ID, UserID: member.ID})
+		if err != nil {
+			return fmt.Errorf("adding list member: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errListFull) {
+			http.Error(w, errListFull.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("Error adding list member: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleRemoveListMember(w http.ResponseWriter, r *http.Request) {
+	list, member, ok := cfg.listMemberTarget(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := cfg.db.RemoveListMember(context.Background(), database.RemoveListMemberParams{ListID: list.ID, UserID: member.ID})
+	if err != nil {
+		log.Printf("Error removing list member: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetListChirps(w http.ResponseWriter, r *http.Request) {
+	viewer, list, ok := cfg.visibleList(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "list timelines are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	chirps, err := cfg.db.GetListChirps(context.Background(), database.GetListChirpsParams{
+		ListID:          list.ID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		ViewerID:        viewer,
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting list timeline from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	chirps, next := trimPage(chirps, page, chirpCursor)
+
+	responses, err := cfg.chirpResponses(context.Background(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses)
+	if err != nil {
+		log.Printf("Error marshalling chirps struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
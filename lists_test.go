@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testList struct {
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+}
+
+func (ts *testServer) createList(user testUser, list map[string]any) testList {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/lists", user.bearer(), list)
+	require.Equal(ts.t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[testList](ts.t, rec)
+}
+
+func (ts *testServer) addListMember(owner testUser, list testList, member testUser) {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPut, "/api/lists/"+list.ID+"/members/"+member.ID, owner.bearer(), nil)
+	require.Equal(ts.t, http.StatusNoContent, rec.Code, rec.Body.String())
+}
+
+func TestLists(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	carol := ts.signup("carol@example.com")
+
+	public := ts.createList(alice, map[string]any{"name": "  friends  ", "description": "people I know"})
+	private := ts.createList(alice, map[string]any{"name": "secret", "private": true})
+
+	t.Run("Create", func(t *testing.T) {
+		assert.Equal(t, "friends", public.Name)
+		assert.Equal(t, alice.ID, public.UserID)
+		assert.False(t, public.IsPrivate)
+		assert.True(t, private.IsPrivate)
+
+		rec := ts.do(http.MethodPost, "/api/lists", "", map[string]any{"name": "anonymous"})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec = ts.do(http.MethodPost, "/api/lists", alice.bearer(), map[string]any{"name": " "})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = ts.do(http.MethodPost, "/api/lists", alice.bearer(), map[string]any{"name": strings.Repeat("a", maxListNameLength+1)})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Private Lists", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/lists/"+private.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		rec = ts.do(http.MethodGet, "/api/lists/"+private.ID, bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = ts.do(http.MethodGet, "/api/lists/"+private.ID+"/chirps", "", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/users/"+alice.ID+"/lists", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, decode[[]testList](t, rec), 2)
+		rec = ts.do(http.MethodGet, "/api/users/"+alice.ID+"/lists", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		lists := decode[[]testList](t, rec)
+		require.Len(t, lists, 1)
+		assert.Equal(t, public.ID, lists[0].ID)
+	})
+
+	t.Run("Owner Only", func(t *testing.T) {
+		rec := ts.do(http.MethodPatch, "/api/lists/"+public.ID, bob.bearer(), map[string]any{"name": "mine now"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		rec = ts.do(http.MethodPut, "/api/lists/"+public.ID+"/members/"+bob.ID, bob.bearer(), nil)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		rec = ts.do(http.MethodPut, "/api/lists/"+private.ID+"/members/"+bob.ID, bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		rec = ts.do(http.MethodDelete, "/api/lists/"+public.ID, "", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		rec := ts.do(http.MethodPatch, "/api/lists/"+public.ID, alice.bearer(), map[string]any{})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = ts.do(http.MethodPatch, "/api/lists/"+public.ID, alice.bearer(), map[string]any{"name": "close friends"})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		updated := decode[testList](t, rec)
+		assert.Equal(t, "close friends", updated.Name)
+		assert.Equal(t, "people I know", updated.Description)
+	})
+
+	t.Run("Timeline", func(t *testing.T) {
+		first := ts.createChirp(bob, "first")
+		ts.createChirp(alice, "not in the list")
+		second := ts.createChirp(carol, "second")
+		third := ts.createChirp(bob, "third")
+
+		path := "/api/lists/" + public.ID + "/chirps"
+		assert.Empty(t, ts.chirpIDs(t, alice, path))
+
+		ts.addListMember(alice, public, bob)
+		ts.addListMember(alice, public, carol)
+		ts.addListMember(alice, public, carol)
+		assert.Equal(t, []string{third.ID, second.ID, first.ID}, ts.chirpIDs(t, alice, path))
+
+		rec := ts.do(http.MethodGet, path+"?limit=2", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		next := rec.Header().Get("X-Next-Cursor")
+		require.NotEmpty(t, next)
+		assert.Equal(t, []string{first.ID}, ts.chirpIDs(t, alice, path+"?limit=2&cursor="+next))
+
+		rec = ts.do(http.MethodGet, path+"?sort=asc", alice.bearer(), nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		// Blocks are applied to the reader, not to the list owner.
+		ts.block(bob, carol)
+		assert.Equal(t, []string{third.ID, second.ID, first.ID}, ts.chirpIDs(t, alice, path))
+		assert.Equal(t, []string{second.ID}, ts.chirpIDs(t, carol, path))
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/lists/"+public.ID+"/members", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		members := decode[[]map[string]any](t, rec)
+		require.Len(t, members, 2)
+		assert.Equal(t, carol.ID, members[0]["id"])
+		assert.Equal(t, bob.ID, members[1]["id"])
+
+		rec = ts.do(http.MethodDelete, "/api/lists/"+public.ID+"/members/"+carol.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Len(t, ts.chirpIDs(t, alice, "/api/lists/"+public.ID+"/chirps"), 2)
+
+		rec = ts.do(http.MethodPut, "/api/lists/"+public.ID+"/members/nobody", alice.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Blocked Members", func(t *testing.T) {
+		dave := ts.signup("dave@example.com")
+		ts.block(dave, alice)
+		rec := ts.do(http.MethodPut, "/api/lists/"+public.ID+"/members/"+dave.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		full := ts.createList(alice, map[string]any{"name": "full"})
+		ts.addListMember(alice, full, bob)
+
+		listID := pgtype.UUID{}
+		require.NoError(t, listID.Scan(full.ID))
+		for i := range maxListMembers - 1 {
+			user, err := ts.store.CreateUser(context.Background(), database.CreateUserParams{Email: fmt.Sprintf("member%d@example.com", i), HashedPassword: "x"})
+			require.NoError(t, err)
+			_, err = ts.store.AddListMember(context.Background(), database.AddListMemberParams{ListID: listID, UserID: user.ID})
+			require.NoError(t, err)
+		}
+
+		rec := ts.do(http.MethodPut, "/api/lists/"+full.ID+"/members/"+carol.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		// Adding an existing member is still a no-op.
+		rec = ts.do(http.MethodPut, "/api/lists/"+full.ID+"/members/"+bob.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, "/api/lists/"+public.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		rec = ts.do(http.MethodGet, "/api/lists/"+public.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
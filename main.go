@@ -5,20 +5,29 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"sync/atomic"
+	"time"
 
+	"github.com/chtozamm/chirpy/internal/blob"
 	"github.com/chtozamm/chirpy/internal/database"
-	"github.com/jackc/pgx/v5"
+	"github.com/chtozamm/chirpy/internal/eventbus"
 	"github.com/joho/godotenv"
 )
 
 type apiConfig struct {
+	// fileserverHits counts the hits of this server not yet added to the
+	// database by flushFileserverHits.
 	fileserverHits atomic.Int32
-	db             *database.Queries
+	db             database.Querier
+	dbPool         *database.Pool
+	blobs          blob.Store
 	filepathRoot   string
 	platform       string
 	authSecret     string
 	polkaKey       string
+	stream         *chirpStream
+	events         eventbus.Bus
 }
 
 func main() {
@@ -31,26 +40,84 @@ func main() {
 	platform := os.Getenv("PLATFORM")
 	authSecret := os.Getenv("AUTH_SECRET")
 	polkaKey := os.Getenv("POLKA_KEY")
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./media"
+	}
+
+	poolConfig := database.PoolConfig{
+		URL:               dbURL,
+		MaxConns:          int32(envInt("DB_MAX_CONNS", 10)),
+		MinConns:          int32(envInt("DB_MIN_CONNS", 0)),
+		HealthCheckPeriod: envDuration("DB_HEALTH_CHECK_PERIOD", 30*time.Second),
+		ConnectTimeout:    envDuration("DB_CONNECT_TIMEOUT", time.Minute),
+	}
 
-	conn, err := pgx.Connect(context.Background(), dbURL)
+	pool, err := database.Connect(context.Background(), poolConfig)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer conn.Close(context.Background())
+	defer pool.Close()
 
-	dbQueries := database.New(conn)
+	if len(os.Args) > 1 {
+		err = runCommand(context.Background(), pool, os.Args[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	migrations, err := openMigrationProvider(pool)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if os.Getenv("AUTO_MIGRATE") == "true" {
+		results, err := migrations.Up(context.Background())
+		logMigrationResults(results)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	err = checkSchemaVersion(context.Background(), migrations)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go pool.Monitor(context.Background(), poolConfig.HealthCheckPeriod)
+
+	dbQueries := database.New(pool)
+
+	blobs, err := blob.NewLocal(mediaDir)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	const filepathRoot = "./static"
 	const port = "8080"
 
+	events := eventbus.NewPostgres(pool.Pool, eventBusChannel, eventIDSequence)
+	go events.Listen(context.Background())
+
 	apiCfg := apiConfig{
 		fileserverHits: atomic.Int32{},
 		db:             dbQueries,
+		dbPool:         pool,
+		blobs:          blobs,
 		platform:       platform,
 		authSecret:     authSecret,
 		polkaKey:       polkaKey,
 		filepathRoot:   filepathRoot,
+		stream:         newChirpStream(envInt("STREAM_MAX_SUBSCRIBERS", 1000), streamReplaySize),
+		events:         events,
 	}
+	apiCfg.events.Subscribe(apiCfg.handleEvent)
+
+	go runPeriodically(context.Background(), "scheduled chirp publishing", envDuration("PUBLISH_INTERVAL", 10*time.Second), apiCfg.publishDueChirps)
+	go runPeriodically(context.Background(), "media garbage collection", envDuration("MEDIA_GC_INTERVAL", time.Hour), func(ctx context.Context) error {
+		return apiCfg.collectOrphanedMedia(ctx, time.Now().Add(-unattachedMediaTTL))
+	})
+	go runPeriodically(context.Background(), "fileserver hits flushing", envDuration("HITS_FLUSH_INTERVAL", 10*time.Second), apiCfg.flushFileserverHits)
+	go runPeriodically(context.Background(), "expired chirp reaping", envDuration("REAP_INTERVAL", time.Minute), apiCfg.reapExpiredChirps)
 
 	mux := getRouter(&apiCfg)
 
@@ -62,3 +129,33 @@ func main() {
 	log.Printf("Serving files from %s on port: %s\n", filepathRoot, port)
 	log.Fatal(srv.ListenAndServe())
 }
+
+// envInt reads an integer environment variable, returning fallback when it
+// is unset or malformed.
+func envInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %d\n", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+// envDuration reads a time.ParseDuration formatted environment variable,
+// returning fallback when it is unset or malformed.
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %v\n", key, value, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chtozamm/chirpy/internal/blob"
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/chtozamm/chirpy/internal/media"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// maxChirpMedia is how many media a chirp can have.
+	maxChirpMedia    = 4
+	maxAltTextLength = 1000
+	// unattachedMediaTTL is how long an upload may wait to be attached to a
+	// chirp before it is garbage-collected.
+	unattachedMediaTTL = 24 * time.Hour
+	mediaGCBatchSize   = 100
+)
+
+// mediaResponse is an attached or uploaded image as the API returns it.
+type mediaResponse struct {
+	ID           pgtype.UUID `json:"id"`
+	URL          string      `json:"url"`
+	ThumbnailURL string      `json:"thumbnail_url"`
+	ContentType  string      `json:"content_type"`
+	Width        int32       `json:"width"`
+	Height       int32       `json:"height"`
+	AltText      string      `json:"alt_text"`
+}
+
+func newMediaResponse(medium database.Medium) mediaResponse {
+	return mediaResponse{
+		ID:           medium.ID,
+		URL:          "/media/" + medium.BlobKey,
+		ThumbnailURL: "/media/" + medium.ThumbnailKey,
+		ContentType:  medium.ContentType,
+		Width:        medium.Width,
+		Height:       medium.Height,
+		AltText:      medium.AltText,
+	}
+}
+
+// handleUploadMedia stores the image sent as the request body. It can then
+// be attached to a chirp by its ID.
+func (cfg *apiConfig) handleUploadMedia(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, media.MaxBytes))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, fmt.Sprintf("media cannot be larger than %d bytes", media.MaxBytes), http.StatusRequestEntityTooLarge)
+			return
+		}
+		log.Printf("Error reading media upload: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	img, err := media.Process(data)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrUnsupported):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, media.ErrTooLarge):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		default:
+			log.Printf("Error processing media upload: %v\n", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	name := uuid.NewString()
+	blobKey := name + img.Original.Ext
+	thumbnailKey := name + "_thumb" + img.Thumbnail.Ext
+
+	err = cfg.blobs.Put(context.Background(), blobKey, bytes.NewReader(img.Original.Data))
+	if err == nil {
+		err = cfg.blobs.Put(context.Background(), thumbnailKey, bytes.NewReader(img.Thumbnail.Data))
+	}
+	if err != nil {
+		log.Printf("Error storing media blobs: %v\n", err)
+		cfg.deleteBlobs(context.Background(), blobKey, thumbnailKey)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	medium, err := cfg.db.CreateMedia(context.Background(), database.CreateMediaParams{
+		UserID:       userID,
+		ContentType:  img.Original.ContentType,
+		Width:        int32(img.Width),
+		Height:       int32(img.Height),
+		BlobKey:      blobKey,
+		ThumbnailKey: thumbnailKey,
+	})
+	if err != nil {
+		log.Printf("Error creating media in db: %v\n", err)
+		cfg.deleteBlobs(context.Background(), blobKey, thumbnailKey)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(newMediaResponse(medium))
+	if err != nil {
+		log.Printf("Error marshalling media struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+// handleGetMedia serves a stored image or thumbnail. Blobs never change, so
+// they can be cached indefinitely.
+func (cfg *apiConfig) handleGetMedia(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if !blob.ValidKey(key) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	f, err := cfg.blobs.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting media blob: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(key)))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if rs, ok := f.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, key, time.Time{}, rs)
+		return
+	}
+	io.Copy(w, f)
+}
+
+// mediaAttachment is an uploaded image to attach to a new chirp.
+type mediaAttachment struct {
+	ID      string `json:"id"`
+	AltText string `json:"alt_text"`
+}
+
+// parseMediaAttachments validates the media of a new chirp and returns the
+// parameters to attach them, without the chirp ID. The error is meant for
+// the client.
+func parseMediaAttachments(userID pgtype.UUID, attachments []mediaAttachment) (database.AttachMediaParams, error) {
+	params := database.AttachMediaParams{UserID: userID}
+	if len(attachments) > maxChirpMedia {
+		return params, fmt.Errorf("a chirp can have at most %d media", maxChirpMedia)
+	}
+
+	for _, attachment := range attachments {
+		id := pgtype.UUID{}
+		err := id.Scan(attachment.ID)
+		if err != nil {
+			return params, errInvalidMedia
+		}
+		if slices.Contains(params.Ids, id) {
+			return params, errors.New("media cannot be attached twice")
+		}
+		if utf8.RuneCountInString(attachment.AltText) > maxAltTextLength {
+			return params, fmt.Errorf("alt_text cannot be longer than %d characters", maxAltTextLength)
+		}
+		params.Ids = append(params.Ids, id)
+		params.AltTexts = append(params.AltTexts, attachment.AltText)
+	}
+	return params, nil
+}
+
+var errInvalidMedia = errors.New("media must be the IDs of your own uploads not yet attached to a chirp")
+
+// checkMediaAttachable returns pgx.ErrNoRows unless every medium in params
+// was uploaded by params.UserID and has never been attached. AttachMedia
+// checks the same, but failing early avoids creating the chirp at all.
+func (cfg *apiConfig) checkMediaAttachable(ctx context.Context, params database.AttachMediaParams) error {
+	media, err := cfg.db.GetMediaByIDs(ctx, params.Ids)
+	if err != nil {
+		return err
+	}
+	if len(media) != len(params.Ids) {
+		return pgx.ErrNoRows
+	}
+	for _, medium := range media {
+		if medium.UserID != params.UserID || medium.AttachedAt.Valid {
+			return pgx.ErrNoRows
+		}
+	}
+	return nil
+}
+
+// chirpMedia looks up the media attached to the chirps with the given IDs,
+// in their attachment order.
+func (cfg *apiConfig) chirpMedia(ctx context.Context, ids []pgtype.UUID) (map[pgtype.UUID][]mediaResponse, error) {
+	rows, err := cfg.db.GetChirpMedia(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	media := make(map[pgtype.UUID][]mediaResponse)
+	for _, medium := range rows {
+		media[medium.ChirpID] = append(media[medium.ChirpID], newMediaResponse(medium))
+	}
+	return media, nil
+}
+
+// collectOrphanedMedia deletes the media of deleted chirps and uploads left
+// unattached since before unattachedBefore, along with their blobs. Rows are
+// deleted before blobs, so a blob whose deletion fails is leaked rather than
+// served broken.
+func (cfg *apiConfig) collectOrphanedMedia(ctx context.Context, unattachedBefore time.Time) error {
+	for {
+		rows, err := cfg.db.DeleteOrphanedMedia(ctx, database.DeleteOrphanedMediaParams{
+			UnattachedBefore: pgtype.Timestamp{Time: unattachedBefore.UTC(), Valid: true},
+			MaxResults:       mediaGCBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			cfg.deleteBlobs(ctx, row.BlobKey, row.ThumbnailKey)
+		}
+		if len(rows) < mediaGCBatchSize {
+			return nil
+		}
+	}
+}
+
+// deleteBlobs deletes blobs on a best-effort basis, logging failures.
+func (cfg *apiConfig) deleteBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		err := cfg.blobs.Delete(ctx, key)
+		if err != nil {
+			log.Printf("Error deleting media blob %s: %v\n", key, err)
+		}
+	}
+}
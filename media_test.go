@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/media"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMedia struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	AltText      string `json:"alt_text"`
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+// uploadRaw sends data as a media upload.
+func (ts *testServer) uploadRaw(user testUser, data []byte) *httptest.ResponseRecorder {
+	ts.t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/media", bytes.NewReader(data))
+	req.Header.Set("Authorization", user.bearer())
+	rec := httptest.NewRecorder()
+	ts.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func (ts *testServer) upload(user testUser) testMedia {
+	ts.t.Helper()
+
+	rec := ts.uploadRaw(user, testPNG(ts.t, 800, 600))
+	require.Equal(ts.t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[testMedia](ts.t, rec)
+}
+
+func (ts *testServer) createChirpWithMedia(user testUser, body string, media ...testMedia) *httptest.ResponseRecorder {
+	ts.t.Helper()
+
+	attachments := []map[string]string{}
+	for _, m := range media {
+		attachments = append(attachments, map[string]string{"id": m.ID, "alt_text": m.AltText})
+	}
+	return ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]any{"body": body, "media": attachments})
+}
+
+func TestUploadMedia(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rec := ts.uploadRaw(testUser{}, testPNG(t, 10, 10))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Unsupported Type", func(t *testing.T) {
+		rec := ts.uploadRaw(user, []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		rec := ts.uploadRaw(user, make([]byte, media.MaxBytes+1))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("Served With Thumbnail", func(t *testing.T) {
+		uploaded := ts.upload(user)
+		assert.Equal(t, "image/png", uploaded.ContentType)
+		assert.Equal(t, 800, uploaded.Width)
+		assert.Equal(t, 600, uploaded.Height)
+
+		rec := ts.do(http.MethodGet, uploaded.URL, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+
+		rec = ts.do(http.MethodGet, uploaded.ThumbnailURL, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		thumb, err := png.DecodeConfig(rec.Body)
+		require.NoError(t, err)
+		assert.Equal(t, media.ThumbnailSize, thumb.Width)
+		assert.Equal(t, 300, thumb.Height)
+	})
+
+	t.Run("Missing Blob", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/media/missing.png", "", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestChirpMedia(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	t.Run("Attached In Order", func(t *testing.T) {
+		first, second := ts.upload(alice), ts.upload(alice)
+		first.AltText = "a blank image"
+
+		rec := ts.createChirpWithMedia(alice, "", second, first)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		chirp := decode[struct {
+			ID    string      `json:"id"`
+			Media []testMedia `json:"media"`
+		}](t, rec)
+		require.Len(t, chirp.Media, 2)
+		assert.Equal(t, second.ID, chirp.Media[0].ID)
+		assert.Equal(t, first.ID, chirp.Media[1].ID)
+		assert.Equal(t, "a blank image", chirp.Media[1].AltText)
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+chirp.ID, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, decode[map[string]any](t, rec)["media"], 2)
+
+		t.Run("Cannot Be Reused", func(t *testing.T) {
+			rec := ts.createChirpWithMedia(alice, "again", first)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	})
+
+	t.Run("Not Own Upload", func(t *testing.T) {
+		rec := ts.createChirpWithMedia(bob, "mine now", ts.upload(alice))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Too Many", func(t *testing.T) {
+		var uploads []testMedia
+		for range 5 {
+			uploads = append(uploads, ts.upload(alice))
+		}
+		rec := ts.createChirpWithMedia(alice, "five", uploads...)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Alt Text Too Long", func(t *testing.T) {
+		uploaded := ts.upload(alice)
+		uploaded.AltText = string(make([]byte, maxAltTextLength+1))
+		rec := ts.createChirpWithMedia(alice, "long", uploaded)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestCollectOrphanedMedia(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+	ctx := context.Background()
+
+	attached, unattached, deleted := ts.upload(user), ts.upload(user), ts.upload(user)
+	rec := ts.createChirpWithMedia(user, "kept", attached)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = ts.createChirpWithMedia(user, "deleted", deleted)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	rec = ts.do(http.MethodDelete, "/api/chirps/"+decode[testChirp](t, rec).ID, user.bearer(), nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	served := func(m testMedia) bool {
+		return ts.do(http.MethodGet, m.URL, "", nil).Code == http.StatusOK
+	}
+
+	require.NoError(t, ts.cfg.collectOrphanedMedia(ctx, time.Now().Add(-unattachedMediaTTL)))
+	assert.True(t, served(attached))
+	assert.True(t, served(unattached), "unattached uploads have a grace period")
+	assert.False(t, served(deleted))
+	assert.Equal(t, http.StatusNotFound, ts.do(http.MethodGet, deleted.ThumbnailURL, "", nil).Code)
+
+	require.NoError(t, ts.cfg.collectOrphanedMedia(ctx, time.Now().Add(time.Minute)))
+	assert.True(t, served(attached))
+	assert.False(t, served(unattached))
+}
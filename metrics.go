@@ -1,11 +1,24 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 )
 
 func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, r *http.Request) {
+	// The count is shared by every server; the hits this one has not added
+	// to it yet are counted too.
+	hits, err := cfg.db.GetFileserverHits(context.Background())
+	if err != nil {
+		log.Printf("Error getting fileserver hits from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	hits += int64(cfg.fileserverHits.Load())
+
 	w.Header().Add("Content-Type", "text/html")
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintf(w, `
@@ -17,7 +30,7 @@ func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, r *http.Request) {
 </body>
 
 </html>
-	`, cfg.fileserverHits.Load())
+	`, hits)
 }
 
 func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
@@ -26,3 +39,36 @@ func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// flushFileserverHits adds the hits counted by this server since the last
+// flush to the count shared by every server. Hits that fail to be added are
+// kept for the next flush.
+func (cfg *apiConfig) flushFileserverHits(ctx context.Context) error {
+	hits := cfg.fileserverHits.Swap(0)
+	if hits == 0 {
+		return nil
+	}
+	err := cfg.db.AddFileserverHits(ctx, int64(hits))
+	if err != nil {
+		cfg.fileserverHits.Add(hits)
+		return err
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerDBStats(w http.ResponseWriter, r *http.Request) {
+	if cfg.dbPool == nil {
+		http.Error(w, "database pool is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	resp, err := json.Marshal(cfg.dbPool.Stats())
+	if err != nil {
+		log.Printf("Error marshalling pool stats: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBStatsWithoutPool(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.do(http.MethodGet, "/admin/db", "", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestFileserverHits(t *testing.T) {
+	ts := newTestServer(t)
+	// Another server sharing the database.
+	other := newTestServer(t)
+	other.cfg.db = ts.store
+
+	metrics := func() string {
+		t.Helper()
+		rec := ts.do(http.MethodGet, "/admin/metrics", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec.Body.String()
+	}
+
+	ts.do(http.MethodGet, "/app/", "", nil)
+	other.do(http.MethodGet, "/app/", "", nil)
+	other.do(http.MethodGet, "/app/", "", nil)
+	assert.Contains(t, metrics(), "visited 1 times")
+
+	t.Run("Shared Once Flushed", func(t *testing.T) {
+		require.NoError(t, other.cfg.flushFileserverHits(context.Background()))
+		assert.Zero(t, other.cfg.fileserverHits.Load())
+		assert.Contains(t, metrics(), "visited 3 times")
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/admin/reset", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, metrics(), "visited 0 times")
+	})
+}
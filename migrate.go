@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"strconv"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+//go:embed sql/schema/*.sql
+var embedMigrations embed.FS
+
+// newMigrationProvider returns a goose provider for the embedded migrations.
+// The session lock keeps several instances from migrating at the same time.
+func newMigrationProvider(db *sql.DB) (*goose.Provider, error) {
+	migrations, err := fs.Sub(embedMigrations, "sql/schema")
+	if err != nil {
+		return nil, err
+	}
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+
+	return goose.NewProvider(goose.DialectPostgres, db, migrations, goose.WithSessionLocker(locker))
+}
+
+// openMigrationProvider wraps the pool in a *sql.DB, which is what goose
+// works with.
+func openMigrationProvider(pool *database.Pool) (*goose.Provider, error) {
+	return newMigrationProvider(stdlib.OpenDBFromPool(pool.Pool))
+}
+
+// runMigrate implements `chirpy migrate up|down|status|to <version>`.
+func runMigrate(ctx context.Context, provider *goose.Provider, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: chirpy migrate up|down|status|to <version>")
+	}
+
+	switch args[0] {
+	case "up":
+		results, err := provider.Up(ctx)
+		logMigrationResults(results)
+		return err
+	case "down":
+		result, err := provider.Down(ctx)
+		if result != nil {
+			logMigrationResults([]*goose.MigrationResult{result})
+		}
+		return err
+	case "status":
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "Pending"
+			if status.State == goose.StateApplied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-24s %s\n", appliedAt, status.Source.Path)
+		}
+		return nil
+	case "to":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: chirpy migrate to <version>")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+
+		current, err := provider.GetDBVersion(ctx)
+		if err != nil {
+			return err
+		}
+
+		var results []*goose.MigrationResult
+		if version >= current {
+			results, err = provider.UpTo(ctx, version)
+		} else {
+			results, err = provider.DownTo(ctx, version)
+		}
+		logMigrationResults(results)
+		return err
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
+
+func logMigrationResults(results []*goose.MigrationResult) {
+	if len(results) == 0 {
+		log.Println("No migrations to run")
+		return
+	}
+	for _, result := range results {
+		log.Println(result)
+	}
+}
+
+// checkSchemaVersion returns an error if the database has not been migrated
+// to the latest embedded migration.
+func checkSchemaVersion(ctx context.Context, provider *goose.Provider) error {
+	current, target, err := provider.GetVersions(ctx)
+	if err != nil {
+		return err
+	}
+	if current < target {
+		return fmt.Errorf("database schema is at version %d but version %d is required; run `chirpy migrate up` or set AUTO_MIGRATE=true", current, target)
+	}
+	return nil
+}
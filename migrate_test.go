@@ -0,0 +1,36 @@
+package main
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	// The provider only needs a *sql.DB handle; nothing connects until a
+	// migration is run.
+	connConfig, err := pgx.ParseConfig("postgres://localhost/chirpy")
+	require.NoError(t, err)
+	db := stdlib.OpenDB(*connConfig)
+	t.Cleanup(func() { db.Close() })
+
+	provider, err := newMigrationProvider(db)
+	require.NoError(t, err)
+
+	sources := provider.ListSources()
+	require.NotEmpty(t, sources)
+
+	for i, source := range sources {
+		assert.Equal(t, int64(i+1), source.Version, "migrations must be numbered sequentially")
+
+		contents, err := fs.ReadFile(embedMigrations, "sql/schema/"+source.Path)
+		require.NoError(t, err)
+		assert.Contains(t, string(contents), "-- +goose Down", source.Path)
+		assert.NotContains(t, strings.ToUpper(string(contents)), "DELETE COLUMN", source.Path)
+	}
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	notificationFollow  = "follow"
+	notificationLike    = "like"
+	notificationMention = "mention"
+	notificationReply   = "reply"
+
+	// maxMentions bounds the notifications a single chirp can send.
+	maxMentions = 10
+)
+
+var notificationTypes = []string{notificationFollow, notificationLike, notificationMention, notificationReply}
+
+// mentionPattern matches an @handle that is not part of an email address or
+// URL.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@&/])@([A-Za-z][A-Za-z0-9_]{2,14})\b`)
+
+// extractMentions returns the distinct, lowercased handles mentioned in body
+// in the order they first appear, up to maxMentions.
+func extractMentions(body string) []string {
+	var handles []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(match[1])
+		if slices.Contains(handles, handle) {
+			continue
+		}
+		handles = append(handles, handle)
+		if len(handles) == maxMentions {
+			break
+		}
+	}
+	return handles
+}
+
+// notify records a notification of kind from actorID to each of userIDs.
+// Notifications are a side effect of the action that triggers them, so a
+// failure is logged rather than failing the request.
+func (cfg *apiConfig) notify(ctx context.Context, actorID pgtype.UUID, kind string, chirpID pgtype.UUID, userIDs ...pgtype.UUID) {
+	_, err := cfg.db.CreateNotifications(ctx, database.CreateNotificationsParams{
+		ActorID: actorID,
+		Type:    kind,
+		ChirpID: chirpID,
+		UserIds: userIDs,
+	})
+	if err != nil {
+		log.Printf("Error creating %s notifications: %v\n", kind, err)
+	}
+}
+
+// retractNotification deletes the notification of an action that was
+// undone, such as a like.
+func (cfg *apiConfig) retractNotification(ctx context.Context, userID, actorID pgtype.UUID, kind string, chirpID pgtype.UUID) {
+	err := cfg.db.DeleteNotification(ctx, database.DeleteNotificationParams{
+		UserID:  userID,
+		Type:    kind,
+		ChirpID: chirpID,
+		ActorID: actorID,
+	})
+	if err != nil {
+		log.Printf("Error deleting %s notification: %v\n", kind, err)
+	}
+}
+
+// notifyChirp notifies the author of the chirp a new chirp replies to and
+// the users it mentions. The author of the parent is only notified of the
+// reply, even if they are mentioned too.
+func (cfg *apiConfig) notifyChirp(ctx context.Context, chirp database.Chirp) error {
+	var parentAuthor pgtype.UUID
+	if chirp.InReplyTo.Valid {
+		parent, err := cfg.db.GetChirp(ctx, chirp.InReplyTo)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("getting parent chirp: %w", err)
+		}
+		if err == nil {
+			parentAuthor = parent.UserID
+			cfg.notify(ctx, chirp.UserID, notificationReply, chirp.ID, parent.UserID)
+		}
+	}
+
+	handles := extractMentions(chirp.Body)
+	if len(handles) == 0 {
+		return nil
+	}
+	users, err := cfg.db.GetUsersByHandles(ctx, handles)
+	if err != nil {
+		return fmt.Errorf("getting mentioned users: %w", err)
+	}
+	var mentioned []pgtype.UUID
+	for _, user := range users {
+		if user.ID != parentAuthor {
+			mentioned = append(mentioned, user.ID)
+		}
+	}
+	if len(mentioned) > 0 {
+		cfg.notify(ctx, chirp.UserID, notificationMention, chirp.ID, mentioned...)
+	}
+	return nil
+}
+
+type notificationGroup struct {
+	Type string `json:"type"`
+	// ChirpID is the liked chirp, or the reply or mention. It is null for
+	// follows.
+	ChirpID     pgtype.UUID      `json:"chirp_id"`
+	Count       int64            `json:"count"`
+	UnreadCount int64            `json:"unread_count"`
+	Actors      []publicProfile  `json:"actors"`
+	LatestAt    pgtype.Timestamp `json:"latest_at"`
+}
+
+func (cfg *apiConfig) handleGetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "notifications are always sorted newest first", http.StatusBadRequest)
+		return
+	}
+
+	rows, err := cfg.db.GetNotificationGroups(context.Background(), database.GetNotificationGroupsParams{
+		UserID:         userID,
+		CursorLatestAt: page.Cursor.Timestamp(),
+		CursorID:       page.Cursor.UUID(),
+		PageSize:       page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting notifications from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rows, next := trimPage(rows, page, func(row database.GetNotificationGroupsRow) pageCursor {
+		return newPageCursor(row.LatestAt, row.LatestID)
+	})
+
+	var actorIDs []pgtype.UUID
+	for _, row := range rows {
+		actorIDs = append(actorIDs, row.ActorIds...)
+	}
+	actors := make(map[pgtype.UUID]publicProfile)
+	if len(actorIDs) > 0 {
+		users, err := cfg.db.GetUsersByIDs(context.Background(), actorIDs)
+		if err != nil {
+			log.Printf("Error getting users from db: %v\n", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		for _, user := range users {
+			actors[user.ID] = newPublicProfile(user)
+		}
+	}
+
+	groups := []notificationGroup{}
+	for _, row := range rows {
+		group := notificationGroup{
+			Type:        row.Type,
+			ChirpID:     row.ChirpID,
+			Count:       row.Count,
+			UnreadCount: row.UnreadCount,
+			Actors:      []publicProfile{},
+			LatestAt:    row.LatestAt,
+		}
+		for _, id := range row.ActorIds {
+			if actor, ok := actors[id]; ok {
+				group.Actors = append(group.Actors, actor)
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	resp, err := json.Marshal(groups)
+	if err != nil {
+		log.Printf("Error marshalling notifications struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleMarkNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	type parameters struct {
+		Type    string `json:"type"`
+		ChirpID string `json:"chirp_id"`
+	}
+
+	// The body is optional: without one every notification is marked read.
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	markParams := database.MarkNotificationsReadParams{UserID: userID}
+	if params.Type != "" {
+		if !slices.Contains(notificationTypes, params.Type) {
+			http.Error(w, fmt.Sprintf("type must be one of %s", strings.Join(notificationTypes, ", ")), http.StatusBadRequest)
+			return
+		}
+		markParams.Type = pgtype.Text{String: params.Type, Valid: true}
+		if params.Type != notificationFollow {
+			err = markParams.ChirpID.Scan(params.ChirpID)
+			if err != nil {
+				http.Error(w, "chirp_id must be a chirp ID", http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	_, err = cfg.db.MarkNotificationsRead(context.Background(), markParams)
+	if err != nil {
+		log.Printf("Error marking notifications read: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleGetUnreadNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	count, err := cfg.db.CountUnreadNotifications(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error counting unread notifications: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	type response struct {
+		UnreadCount int64 `json:"unread_count"`
+	}
+
+	resp, err := json.Marshal(response{UnreadCount: count})
+	if err != nil {
+		log.Printf("Error marshalling response struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
+
+// writeNotificationPreferences responds with whether each type of
+// notification is enabled for userID.
+func (cfg *apiConfig) writeNotificationPreferences(w http.ResponseWriter, userID pgtype.UUID) {
+	disabled, err := cfg.db.GetDisabledNotificationTypes(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error getting notification preferences from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	preferences := make(map[string]bool)
+	for _, kind := range notificationTypes {
+		preferences[kind] = !slices.Contains(disabled, kind)
+	}
+
+	resp, err := json.Marshal(preferences)
+	if err != nil {
+		log.Printf("Error marshalling preferences: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleGetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	cfg.writeNotificationPreferences(w, userID)
+}
+
+func (cfg *apiConfig) handleUpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := map[string]bool{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	for kind := range params {
+		if !slices.Contains(notificationTypes, kind) {
+			http.Error(w, fmt.Sprintf("unknown notification type %q", kind), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for kind, enabled := range params {
+		if enabled {
+			err = cfg.db.EnableNotificationType(context.Background(), database.EnableNotificationTypeParams{UserID: userID, Type: kind})
+		} else {
+			err = cfg.db.DisableNotificationType(context.Background(), database.DisableNotificationTypeParams{UserID: userID, Type: kind})
+		}
+		if err != nil {
+			log.Printf("Error updating notification preferences: %v\n", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	cfg.writeNotificationPreferences(w, userID)
+}
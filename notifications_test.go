@@ -0,0 +1,178 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNotificationGroup struct {
+	Type        string  `json:"type"`
+	ChirpID     *string `json:"chirp_id"`
+	Count       int64   `json:"count"`
+	UnreadCount int64   `json:"unread_count"`
+	Actors      []struct {
+		ID string `json:"id"`
+	} `json:"actors"`
+}
+
+func (ts *testServer) notifications(t *testing.T, user testUser) []testNotificationGroup {
+	t.Helper()
+
+	rec := ts.do(http.MethodGet, "/api/notifications", user.bearer(), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	return decode[[]testNotificationGroup](t, rec)
+}
+
+func (ts *testServer) unreadNotifications(t *testing.T, user testUser) int64 {
+	t.Helper()
+
+	rec := ts.do(http.MethodGet, "/api/notifications/unread", user.bearer(), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	return decode[map[string]int64](t, rec)["unread_count"]
+}
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"None", "no mentions here", nil},
+		{"Normalized And Deduplicated", "@Bob and @bob", []string{"bob"}},
+		{"Order Of Appearance", "@carol then @alice!", []string{"carol", "alice"}},
+		{"Email", "mail alice@example.com", nil},
+		{"Too Short", "@ab", nil},
+		{"Too Long", "@abcdefghijklmnop", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractMentions(tt.body))
+		})
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	carol := ts.signup("carol@example.com")
+	for user, handle := range map[testUser]string{alice: "alice", bob: "bob", carol: "carol"} {
+		rec := ts.do(http.MethodPut, "/api/users", user.bearer(), map[string]string{"handle": handle})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	chirp := ts.createChirp(alice, "hello")
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/notifications", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Likes Are Grouped", func(t *testing.T) {
+		ts.like(bob, chirp)
+		ts.like(carol, chirp)
+		ts.like(alice, chirp)
+
+		groups := ts.notifications(t, alice)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "like", groups[0].Type)
+		assert.Equal(t, &chirp.ID, groups[0].ChirpID)
+		assert.Equal(t, int64(2), groups[0].Count)
+		require.Len(t, groups[0].Actors, 2)
+		assert.Equal(t, carol.ID, groups[0].Actors[0].ID)
+		assert.Equal(t, int64(1), ts.unreadNotifications(t, alice))
+
+		// Unliking retracts the notification.
+		rec := ts.do(http.MethodDelete, "/api/chirps/"+chirp.ID+"/like", carol.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, int64(1), ts.notifications(t, alice)[0].Count)
+	})
+
+	t.Run("Follows, Replies And Mentions", func(t *testing.T) {
+		ts.follow(bob, alice)
+		reply := ts.reply(carol, chirp.ID, "@alice @bob good point")
+
+		groups := ts.notifications(t, alice)
+		require.Len(t, groups, 3)
+		assert.Equal(t, "reply", groups[0].Type)
+		assert.Equal(t, &reply.ID, groups[0].ChirpID)
+		assert.Equal(t, "follow", groups[1].Type)
+		assert.Nil(t, groups[1].ChirpID)
+
+		// Bob is mentioned but was not replied to.
+		groups = ts.notifications(t, bob)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "mention", groups[0].Type)
+		assert.Equal(t, carol.ID, groups[0].Actors[0].ID)
+	})
+
+	t.Run("Mark Read", func(t *testing.T) {
+		assert.Equal(t, int64(3), ts.unreadNotifications(t, alice))
+
+		rec := ts.do(http.MethodPost, "/api/notifications/read", alice.bearer(), map[string]string{"type": "follow"})
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, int64(2), ts.unreadNotifications(t, alice))
+
+		rec = ts.do(http.MethodPost, "/api/notifications/read", alice.bearer(), map[string]string{"type": "like"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = ts.do(http.MethodPost, "/api/notifications/read", alice.bearer(), map[string]string{"type": "like", "chirp_id": chirp.ID})
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, int64(1), ts.unreadNotifications(t, alice))
+
+		rec = ts.do(http.MethodPost, "/api/notifications/read", alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, int64(0), ts.unreadNotifications(t, alice))
+		for _, group := range ts.notifications(t, alice) {
+			assert.Zero(t, group.UnreadCount, group.Type)
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/notifications?limit=2", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, decode[[]testNotificationGroup](t, rec), 2)
+		next := rec.Header().Get("X-Next-Cursor")
+		require.NotEmpty(t, next)
+
+		rec = ts.do(http.MethodGet, "/api/notifications?limit=2&cursor="+next, alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		groups := decode[[]testNotificationGroup](t, rec)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "like", groups[0].Type)
+	})
+
+	t.Run("Preferences", func(t *testing.T) {
+		rec := ts.do(http.MethodPut, "/api/notifications/preferences", bob.bearer(), map[string]bool{"like": false})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, map[string]bool{"follow": true, "like": false, "mention": true, "reply": true}, decode[map[string]bool](t, rec))
+
+		rec = ts.do(http.MethodPut, "/api/notifications/preferences", bob.bearer(), map[string]bool{"poke": false})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		bobs := ts.createChirp(bob, "like this")
+		ts.like(alice, bobs)
+		assert.Len(t, ts.notifications(t, bob), 1)
+
+		ts.follow(alice, bob)
+		assert.Len(t, ts.notifications(t, bob), 2)
+	})
+
+	t.Run("Blocked And Muted Actors", func(t *testing.T) {
+		dave := ts.signup("dave@example.com")
+		ts.follow(dave, alice)
+		assert.Equal(t, int64(1), ts.unreadNotifications(t, alice))
+
+		// Blocking hides earlier notifications too.
+		ts.block(alice, dave)
+		assert.Equal(t, int64(0), ts.unreadNotifications(t, alice))
+
+		rec := ts.do(http.MethodPost, "/api/users/"+carol.ID+"/mute", alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		ts.follow(carol, alice)
+		assert.Equal(t, int64(0), ts.unreadNotifications(t, alice))
+	})
+}
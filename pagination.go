@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// pageCursor is a position in a list ordered by (created_at, id), or by
+// (rank, created_at, id) for search results. It is handed to clients as an
+// opaque string.
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+	Rank      float32
+}
+
+// Sentinel cursors that sort before and after every row.
+var (
+	firstCursor = pageCursor{CreatedAt: time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	lastCursor  = pageCursor{CreatedAt: time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC), ID: uuid.Max}
+)
+
+func newPageCursor(createdAt pgtype.Timestamp, id pgtype.UUID) pageCursor {
+	return pageCursor{CreatedAt: createdAt.Time, ID: id.Bytes}
+}
+
+func (c pageCursor) String() string {
+	raw := fmt.Sprintf("%d:%s", c.CreatedAt.UnixMicro(), c.ID)
+	if c.Rank != 0 {
+		raw += ":" + strconv.FormatFloat(float64(c.Rank), 'g', -1, 32)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func (c pageCursor) Timestamp() pgtype.Timestamp {
+	return pgtype.Timestamp{Time: c.CreatedAt, Valid: true}
+}
+
+func (c pageCursor) UUID() pgtype.UUID {
+	return pgtype.UUID{Bytes: c.ID, Valid: true}
+}
+
+func parsePageCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, err
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+
+	micros, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return pageCursor{}, err
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return pageCursor{}, err
+	}
+
+	cursor := pageCursor{CreatedAt: time.UnixMicro(micros).UTC(), ID: id}
+	if len(parts) == 3 {
+		rank, err := strconv.ParseFloat(parts[2], 32)
+		if err != nil {
+			return pageCursor{}, err
+		}
+		cursor.Rank = float32(rank)
+	}
+
+	return cursor, nil
+}
+
+// pageRequest holds the pagination query parameters of a list request:
+// limit, cursor and sort ("asc" or "desc").
+type pageRequest struct {
+	Cursor pageCursor
+	Limit  int32
+	Desc   bool
+}
+
+// parsePageRequest reads limit, cursor and sort from the query string. When
+// no cursor is given the page starts at the beginning of the requested order.
+func parsePageRequest(r *http.Request, defaultDesc bool) (pageRequest, error) {
+	query := r.URL.Query()
+	page := pageRequest{Limit: defaultPageSize, Desc: defaultDesc}
+
+	switch query.Get("sort") {
+	case "":
+	case "asc":
+		page.Desc = false
+	case "desc":
+		page.Desc = true
+	default:
+		return pageRequest{}, errors.New("sort must be asc or desc")
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return pageRequest{}, errors.New("limit must be a positive integer")
+		}
+		page.Limit = int32(min(n, maxPageSize))
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		c, err := parsePageCursor(cursor)
+		if err != nil {
+			return pageRequest{}, errors.New("invalid cursor")
+		}
+		page.Cursor = c
+	} else if page.Desc {
+		page.Cursor = lastCursor
+	} else {
+		page.Cursor = firstCursor
+	}
+
+	return page, nil
+}
+
+// QueryLimit is the number of rows to fetch: one more than the page size so
+// that the presence of a next page can be detected.
+func (p pageRequest) QueryLimit() int32 {
+	return p.Limit + 1
+}
+
+// trimPage drops the look-ahead row fetched by QueryLimit and returns the
+// cursor of the next page, or nil if items is the last page.
+func trimPage[T any](items []T, p pageRequest, cursorOf func(T) pageCursor) ([]T, *pageCursor) {
+	if p.Limit == 0 && len(items) > 0 {
+		// Only the look-ahead row was fetched, so the next page starts
+		// where this one did.
+		next := p.Cursor
+		return []T{}, &next
+	}
+	if len(items) <= int(p.Limit) {
+		if items == nil {
+			items = []T{}
+		}
+		return items, nil
+	}
+
+	items = items[:p.Limit]
+	next := cursorOf(items[len(items)-1])
+	return items, &next
+}
+
+// setNextPageHeaders advertises the next page through a Link header and an
+// X-Next-Cursor header, keeping the other query parameters of the request.
+func setNextPageHeaders(w http.ResponseWriter, r *http.Request, next *pageCursor) {
+	if next == nil {
+		return
+	}
+
+	query := r.URL.Query()
+	query.Set("cursor", next.String())
+	nextURL := *r.URL
+	nextURL.RawQuery = query.Encode()
+
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, nextURL.RequestURI()))
+	w.Header().Set("X-Next-Cursor", next.String())
+}
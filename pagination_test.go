@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCursor(t *testing.T) {
+	t.Run("Round Trip", func(t *testing.T) {
+		cursor := pageCursor{CreatedAt: time.Now().UTC().Truncate(time.Microsecond), ID: uuid.New()}
+
+		parsed, err := parsePageCursor(cursor.String())
+		require.NoError(t, err)
+		assert.True(t, cursor.CreatedAt.Equal(parsed.CreatedAt))
+		assert.Equal(t, cursor.ID, parsed.ID)
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		_, err := parsePageCursor("bm90LWEtY3Vyc29y")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (cfg *apiConfig) handlePinChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	type parameters struct {
+		ChirpID string `json:"chirp_id"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Unknown chirps, other users' chirps and rechirps are all refused the
+	// same way: only the pinning query can tell them apart without a race.
+	const invalidChirp = "chirp_id must be the ID of one of your chirps"
+	chirpID := pgtype.UUID{}
+	err = chirpID.Scan(params.ChirpID)
+	if err != nil {
+		http.Error(w, invalidChirp, http.StatusBadRequest)
+		return
+	}
+
+	pinned, err := cfg.db.PinChirp(context.Background(), database.PinChirpParams{ChirpID: chirpID, UserID: userID})
+	if err != nil {
+		log.Printf("Error pinning chirp: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if pinned == 0 {
+		http.Error(w, invalidChirp, http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handleUnpinChirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	err = cfg.db.UnpinChirp(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error unpinning chirp: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (ts *testServer) pinnedChirpID(t *testing.T, user testUser) any {
+	t.Helper()
+
+	rec := ts.do(http.MethodGet, "/api/users/"+user.ID, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	return decode[map[string]any](t, rec)["pinned_chirp_id"]
+}
+
+func TestPinChirp(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	first := ts.createChirp(alice, "first")
+	second := ts.createChirp(alice, "second")
+	third := ts.createChirp(alice, "third")
+	bobs := ts.createChirp(bob, "not alice's")
+	authorPath := "/api/chirps?author_id=" + alice.ID
+
+	pin := func(user testUser, chirpID string) int {
+		return ts.do(http.MethodPut, "/api/users/me/pinned", user.bearer(), map[string]string{"chirp_id": chirpID}).Code
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rec := ts.do(http.MethodPut, "/api/users/me/pinned", "", map[string]string{"chirp_id": first.ID})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Own Chirps Only", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, pin(alice, bobs.ID))
+		assert.Equal(t, http.StatusBadRequest, pin(alice, "not-a-uuid"))
+
+		rec := ts.do(http.MethodPost, "/api/chirps/"+first.ID+"/rechirp", bob.bearer(), nil)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		rechirp := decode[testChirp](t, rec)
+		assert.Equal(t, http.StatusBadRequest, pin(bob, rechirp.ID))
+		assert.Nil(t, ts.pinnedChirpID(t, alice))
+		assert.Nil(t, ts.pinnedChirpID(t, bob))
+	})
+
+	t.Run("Pinned First", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, pin(alice, second.ID))
+		assert.Equal(t, second.ID, ts.pinnedChirpID(t, alice))
+
+		rec := ts.do(http.MethodGet, authorPath+"&limit=2", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		page := decode[[]map[string]any](t, rec)
+		require.Len(t, page, 2, "the pinned chirp counts towards the limit")
+		assert.Equal(t, second.ID, page[0]["id"])
+		assert.Equal(t, true, page[0]["pinned"])
+		assert.Equal(t, first.ID, page[1]["id"])
+		assert.Nil(t, page[1]["pinned"])
+
+		// The pinned chirp is not repeated in its place in the list.
+		next := rec.Header().Get("X-Next-Cursor")
+		require.NotEmpty(t, next)
+		assert.Equal(t, []string{third.ID}, ts.chirpIDs(t, bob, authorPath+"&limit=2&cursor="+next))
+		assert.Equal(t, []string{second.ID, third.ID, first.ID}, ts.chirpIDs(t, bob, authorPath+"&sort=desc"))
+
+		// Lists not filtered by author are unchanged.
+		assert.Equal(t, first.ID, ts.chirpIDs(t, bob, "/api/chirps")[0])
+	})
+
+	t.Run("Pinned Chirp Fills The Page", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, authorPath+"&limit=1", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		page := decode[[]map[string]any](t, rec)
+		require.Len(t, page, 1)
+		assert.Equal(t, second.ID, page[0]["id"])
+
+		// The next page starts from the beginning of the rest of the list.
+		next := rec.Header().Get("X-Next-Cursor")
+		require.NotEmpty(t, next)
+		rec = ts.do(http.MethodGet, authorPath+"&limit=1&cursor="+next, bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, first.ID, decode[[]map[string]any](t, rec)[0]["id"])
+		next = rec.Header().Get("X-Next-Cursor")
+		require.NotEmpty(t, next)
+		assert.Equal(t, []string{third.ID}, ts.chirpIDs(t, bob, authorPath+"&limit=1&cursor="+next))
+	})
+
+	t.Run("Hidden From Blocked Users", func(t *testing.T) {
+		carol := ts.signup("carol@example.com")
+		ts.block(alice, carol)
+		assert.Empty(t, ts.chirpIDs(t, carol, authorPath))
+	})
+
+	t.Run("Replace And Unpin", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, pin(alice, third.ID))
+		assert.Equal(t, third.ID, ts.pinnedChirpID(t, alice))
+
+		rec := ts.do(http.MethodDelete, "/api/users/me/pinned", alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Nil(t, ts.pinnedChirpID(t, alice))
+		assert.Equal(t, []string{first.ID, second.ID, third.ID}, ts.chirpIDs(t, bob, authorPath))
+	})
+
+	t.Run("Deleting Unpins", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, pin(alice, first.ID))
+
+		rec := ts.do(http.MethodDelete, "/api/chirps/"+first.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Nil(t, ts.pinnedChirpID(t, alice))
+		assert.Equal(t, []string{second.ID, third.ID}, ts.chirpIDs(t, bob, authorPath))
+	})
+}
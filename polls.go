@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 50
+	minPollDuration     = 5 * time.Minute
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+// pollParameters is the poll of a new chirp.
+type pollParameters struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+// parsePoll validates the poll of a new chirp and returns the parameters to
+// create it, without the chirp ID. The error is meant for the client.
+func parsePoll(params pollParameters) (database.CreatePollParams, error) {
+	if len(params.Options) < minPollOptions || len(params.Options) > maxPollOptions {
+		return database.CreatePollParams{}, fmt.Errorf("a poll must have %d to %d options", minPollOptions, maxPollOptions)
+	}
+
+	options := make([]string, 0, len(params.Options))
+	for _, option := range params.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return database.CreatePollParams{}, errors.New("poll options cannot be empty")
+		}
+		if utf8.RuneCountInString(option) > maxPollOptionLength {
+			return database.CreatePollParams{}, fmt.Errorf("poll options cannot be longer than %d characters", maxPollOptionLength)
+		}
+		if slices.Contains(options, option) {
+			return database.CreatePollParams{}, errors.New("poll options must be unique")
+		}
+		options = append(options, option)
+	}
+
+	duration := time.Until(params.ClosesAt)
+	if duration < minPollDuration || duration > maxPollDuration {
+		return database.CreatePollParams{}, fmt.Errorf("poll closes_at must be between %v and %v from now", minPollDuration, maxPollDuration)
+	}
+
+	return database.CreatePollParams{
+		Options:  options,
+		ClosesAt: pgtype.Timestamp{Time: params.ClosesAt.UTC(), Valid: true},
+	}, nil
+}
+
+type pollOptionResponse struct {
+	Text  string `json:"text"`
+	Votes *int64 `json:"votes,omitempty"`
+}
+
+// pollResponse is a poll as seen by the viewer. Results, the votes of each
+// option and TotalVotes, are only included once the viewer has voted or the
+// poll has closed, so that they cannot sway the vote.
+type pollResponse struct {
+	Options    []pollOptionResponse `json:"options"`
+	ClosesAt   pgtype.Timestamp     `json:"closes_at"`
+	Closed     bool                 `json:"closed"`
+	TotalVotes *int64               `json:"total_votes,omitempty"`
+	MyVote     *int32               `json:"my_vote"`
+}
+
+// chirpPolls looks up the polls of the chirps with the given IDs. It needs a
+// single query when none of the chirps has a poll. viewer is invalid for
+// anonymous requests.
+func (cfg *apiConfig) chirpPolls(ctx context.Context, viewer pgtype.UUID, ids []pgtype.UUID) (map[pgtype.UUID]*pollResponse, error) {
+	responses := make(map[pgtype.UUID]*pollResponse)
+
+	polls, err := cfg.db.GetPolls(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(polls) == 0 {
+		return responses, nil
+	}
+
+	pollIDs := make([]pgtype.UUID, len(polls))
+	for i, poll := range polls {
+		pollIDs[i] = poll.ChirpID
+	}
+
+	counts, err := cfg.db.GetPollVoteCounts(ctx, pollIDs)
+	if err != nil {
+		return nil, err
+	}
+	votes := make(map[pgtype.UUID][]int64)
+	for _, poll := range polls {
+		votes[poll.ChirpID] = make([]int64, len(poll.Options))
+	}
+	for _, row := range counts {
+		if int(row.OptionIndex) < len(votes[row.ChirpID]) {
+			votes[row.ChirpID][row.OptionIndex] = row.VoteCount
+		}
+	}
+
+	myVotes := make(map[pgtype.UUID]int32)
+	if viewer.Valid {
+		rows, err := cfg.db.GetPollVotes(ctx, database.GetPollVotesParams{UserID: viewer, ChirpIds: pollIDs})
+		if err != nil {
+			return nil, err
+		}
+		for _, vote := range rows {
+			myVotes[vote.ChirpID] = vote.OptionIndex
+		}
+	}
+
+	now := time.Now()
+	for _, poll := range polls {
+		response := &pollResponse{
+			Options:  make([]pollOptionResponse, len(poll.Options)),
+			ClosesAt: poll.ClosesAt,
+			Closed:   !poll.ClosesAt.Time.After(now),
+		}
+		myVote, voted := myVotes[poll.ChirpID]
+		if voted {
+			response.MyVote = &myVote
+		}
+
+		var total int64
+		for i, option := range poll.Options {
+			response.Options[i].Text = option
+			if voted || response.Closed {
+				response.Options[i].Votes = &votes[poll.ChirpID][i]
+			}
+			total += votes[poll.ChirpID][i]
+		}
+		if voted || response.Closed {
+			response.TotalVotes = &total
+		}
+		responses[poll.ChirpID] = response
+	}
+	return responses, nil
+}
+
+func (cfg *apiConfig) handleVotePoll(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	chirpID := pgtype.UUID{}
+	err = chirpID.Scan(r.PathValue("chirpID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	type parameters struct {
+		Option *int32 `json:"option"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if params.Option == nil {
+		http.Error(w, "option is required", http.StatusBadRequest)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(context.Background(), chirpID)
+	if err == nil {
+		err = cfg.checkNotBlocked(context.Background(), userID, chirp)
+	}
+	var poll database.Poll
+	if err == nil {
+		poll, err = cfg.db.GetPoll(context.Background(), chirpID)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting poll from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if !poll.ClosesAt.Time.After(time.Now()) {
+		http.Error(w, "the poll is closed", http.StatusConflict)
+		return
+	}
+	if *params.Option < 0 || int(*params.Option) >= len(poll.Options) {
+		http.Error(w, fmt.Sprintf("option must be between 0 and %d", len(poll.Options)-1), http.StatusBadRequest)
+		return
+	}
+
+	// The insert checks again that the poll is open, and the primary key
+	// keeps concurrent votes by the same user from counting twice.
+	voted, err := cfg.db.CreatePollVote(context.Background(), database.CreatePollVoteParams{
+		UserID:      userID,
+		OptionIndex: *params.Option,
+		ChirpID:     chirpID,
+	})
+	if err != nil {
+		log.Printf("Error creating poll vote: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if voted == 0 {
+		// Either the user has voted or the poll closed since it was checked.
+		if !poll.ClosesAt.Time.After(time.Now()) {
+			http.Error(w, "the poll is closed", http.StatusConflict)
+			return
+		}
+		http.Error(w, "you have already voted in this poll", http.StatusConflict)
+		return
+	}
+
+	polls, err := cfg.chirpPolls(context.Background(), userID, []pgtype.UUID{chirpID})
+	if err != nil {
+		log.Printf("Error getting poll results from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(polls[chirpID])
+	if err != nil {
+		log.Printf("Error marshalling poll struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPoll struct {
+	Options []struct {
+		Text  string `json:"text"`
+		Votes *int64 `json:"votes"`
+	} `json:"options"`
+	Closed     bool   `json:"closed"`
+	TotalVotes *int64 `json:"total_votes"`
+	MyVote     *int32 `json:"my_vote"`
+}
+
+func (ts *testServer) createPoll(user testUser, options ...string) testChirp {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]any{
+		"body": "which one?",
+		"poll": map[string]any{"options": options, "closes_at": time.Now().Add(time.Hour)},
+	})
+	require.Equal(ts.t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[testChirp](ts.t, rec)
+}
+
+func (ts *testServer) getPoll(t *testing.T, user testUser, chirp testChirp) testPoll {
+	t.Helper()
+
+	rec := ts.do(http.MethodGet, "/api/chirps/"+chirp.ID, user.bearer(), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	return decode[struct {
+		Poll testPoll `json:"poll"`
+	}](t, rec).Poll
+}
+
+func (ts *testServer) vote(user testUser, chirp testChirp, option int) *httptest.ResponseRecorder {
+	ts.t.Helper()
+
+	return ts.do(http.MethodPost, "/api/chirps/"+chirp.ID+"/poll/votes", user.bearer(), map[string]int{"option": option})
+}
+
+func TestCreatePoll(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+
+	for name, poll := range map[string]map[string]any{
+		"One Option":      {"options": []string{"yes"}, "closes_at": time.Now().Add(time.Hour)},
+		"Five Options":    {"options": []string{"a", "b", "c", "d", "e"}, "closes_at": time.Now().Add(time.Hour)},
+		"Duplicate":       {"options": []string{"yes", " yes"}, "closes_at": time.Now().Add(time.Hour)},
+		"Empty Option":    {"options": []string{"yes", ""}, "closes_at": time.Now().Add(time.Hour)},
+		"Closes Too Soon": {"options": []string{"yes", "no"}, "closes_at": time.Now().Add(time.Minute)},
+		"Closes Too Late": {"options": []string{"yes", "no"}, "closes_at": time.Now().Add(30 * 24 * time.Hour)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]any{"body": "poll", "poll": poll})
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		chirp := ts.createPoll(user, "yes", "no")
+		poll := ts.getPoll(t, user, chirp)
+		require.Len(t, poll.Options, 2)
+		assert.Equal(t, "no", poll.Options[1].Text)
+		assert.False(t, poll.Closed)
+	})
+}
+
+func TestVotePoll(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	carol := ts.signup("carol@example.com")
+	chirp := ts.createPoll(alice, "yes", "no", "maybe")
+
+	t.Run("Results Hidden Before Voting", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, ts.vote(alice, chirp, 0).Code)
+
+		poll := ts.getPoll(t, bob, chirp)
+		assert.Nil(t, poll.TotalVotes)
+		assert.Nil(t, poll.MyVote)
+		for _, option := range poll.Options {
+			assert.Nil(t, option.Votes)
+		}
+	})
+
+	t.Run("Results Shown After Voting", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, ts.vote(bob, chirp, 2).Code)
+
+		poll := ts.getPoll(t, bob, chirp)
+		require.NotNil(t, poll.TotalVotes)
+		assert.EqualValues(t, 2, *poll.TotalVotes)
+		require.NotNil(t, poll.MyVote)
+		assert.EqualValues(t, 2, *poll.MyVote)
+		assert.EqualValues(t, 1, *poll.Options[0].Votes)
+		assert.EqualValues(t, 0, *poll.Options[1].Votes)
+	})
+
+	t.Run("Once Per User", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, ts.vote(bob, chirp, 1).Code)
+	})
+
+	t.Run("Invalid Option", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, ts.vote(carol, chirp, 3).Code)
+	})
+
+	t.Run("No Poll", func(t *testing.T) {
+		plain := ts.createChirp(alice, "no poll here")
+		assert.Equal(t, http.StatusNotFound, ts.vote(carol, plain, 0).Code)
+	})
+
+	t.Run("Concurrent Votes Count Once", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ts.vote(carol, chirp, 1)
+			}()
+		}
+		wg.Wait()
+
+		poll := ts.getPoll(t, carol, chirp)
+		assert.EqualValues(t, 3, *poll.TotalVotes)
+	})
+}
+
+func TestClosedPoll(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	chirp := ts.createChirp(alice, "which one?")
+	chirpID := pgtype.UUID{}
+	require.NoError(t, chirpID.Scan(chirp.ID))
+	_, err := ts.store.CreatePoll(context.Background(), database.CreatePollParams{
+		ChirpID:  chirpID,
+		Options:  []string{"yes", "no"},
+		ClosesAt: pgtype.Timestamp{Time: time.Now().Add(-time.Minute).UTC(), Valid: true},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusConflict, ts.vote(bob, chirp, 0).Code)
+
+	poll := ts.getPoll(t, bob, chirp)
+	assert.True(t, poll.Closed)
+	require.NotNil(t, poll.TotalVotes, "results are public once closed")
+	assert.EqualValues(t, 0, *poll.TotalVotes)
+}
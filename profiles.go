@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+	maxAvatarURLLength   = 2048
+)
+
+var handlePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{2,14}$`)
+
+// reservedHandles cannot be registered because they collide with routes,
+// would impersonate staff or confuse clients.
+var reservedHandles = []string{
+	"about", "admin", "administrator", "api", "app", "assets", "chirpy",
+	"help", "login", "logout", "me", "mod", "moderator", "null", "official",
+	"root", "security", "settings", "signup", "staff", "static", "support",
+	"system", "undefined",
+}
+
+// validateHandle checks that a handle is 3 to 15 letters, digits or
+// underscores, starts with a letter and is not reserved.
+func validateHandle(handle string) error {
+	if !handlePattern.MatchString(handle) {
+		return errors.New("handle must be 3 to 15 letters, digits or underscores and start with a letter")
+	}
+	if slices.Contains(reservedHandles, strings.ToLower(handle)) {
+		return fmt.Errorf("handle %q is reserved", handle)
+	}
+	return nil
+}
+
+func validateDisplayName(name string) error {
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		return fmt.Errorf("display_name cannot be longer than %d characters", maxDisplayNameLength)
+	}
+	return nil
+}
+
+func validateBio(bio string) error {
+	if utf8.RuneCountInString(bio) > maxBioLength {
+		return fmt.Errorf("bio cannot be longer than %d characters", maxBioLength)
+	}
+	return nil
+}
+
+// validateAvatarURL accepts an empty string, which removes the avatar, or an
+// absolute http(s) URL.
+func validateAvatarURL(avatarURL string) error {
+	if avatarURL == "" {
+		return nil
+	}
+	if len(avatarURL) > maxAvatarURLLength {
+		return fmt.Errorf("avatar_url cannot be longer than %d characters", maxAvatarURLLength)
+	}
+	u, err := url.Parse(avatarURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("avatar_url must be an http or https URL")
+	}
+	return nil
+}
+
+// userConflictMessage describes a unique violation on the users table, or
+// returns false if err is not one.
+func userConflictMessage(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.UniqueViolation {
+		return "", false
+	}
+	if pgErr.ConstraintName == "users_handle_key" {
+		return "handle already taken", true
+	}
+	return "email already exists", true
+}
+
+// publicProfile is the part of a user that anyone may see. It must never
+// include the email or the password hash.
+type publicProfile struct {
+	ID          pgtype.UUID      `json:"id"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	Handle      pgtype.Text      `json:"handle"`
+	DisplayName string           `json:"display_name"`
+	Bio         string           `json:"bio"`
+	AvatarURL   string           `json:"avatar_url"`
+	IsChirpyRed bool             `json:"is_chirpy_red"`
+}
+
+func newPublicProfile(user database.User) publicProfile {
+	return publicProfile{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		Handle:      user.Handle,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarUrl,
+		IsChirpyRed: user.IsChirpyRed,
+	}
+}
+
+// lookupUser finds a user by UUID or, failing that, by handle. Handles can
+// never be mistaken for UUIDs because they cannot contain dashes.
+func (cfg *apiConfig) lookupUser(ctx context.Context, idOrHandle string) (database.User, error) {
+	id := pgtype.UUID{}
+	if err := id.Scan(idOrHandle); err == nil {
+		return cfg.db.GetUserByID(ctx, id)
+	}
+	if !handlePattern.MatchString(idOrHandle) {
+		return database.User{}, pgx.ErrNoRows
+	}
+	return cfg.db.GetUserByHandle(ctx, idOrHandle)
+}
+
+func (cfg *apiConfig) handleGetProfile(w http.ResponseWriter, r *http.Request) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	user, err := cfg.lookupUser(context.Background(), r.PathValue("user"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting user from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	counts, err := cfg.db.GetFollowCounts(context.Background(), user.ID)
+	if err != nil {
+		log.Printf("Error getting follow counts from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Only a pinned chirp the viewer could open is shown.
+	pinned, err := cfg.db.GetPinnedChirp(context.Background(), database.GetPinnedChirpParams{UserID: user.ID, ViewerID: viewer})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("Error getting pinned chirp from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	type response struct {
+		publicProfile
+		FollowersCount int64       `json:"followers_count"`
+		FollowingCount int64       `json:"following_count"`
+		PinnedChirpID  pgtype.UUID `json:"pinned_chirp_id"`
+	}
+
+	resp, err := json.Marshal(response{
+		publicProfile:  newPublicProfile(user),
+		FollowersCount: counts.FollowersCount,
+		FollowingCount: counts.FollowingCount,
+		PinnedChirpID:  pinned.ID,
+	})
+	if err != nil {
+		log.Printf("Error marshalling profile: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
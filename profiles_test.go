@@ -0,0 +1,111 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateHandle(t *testing.T) {
+	for _, handle := range []string{"bob", "Bob_99", "a_long_handle_x"} {
+		assert.NoError(t, validateHandle(handle), handle)
+	}
+	for _, handle := range []string{"", "ab", "1bob", "_bob", "bob-smith", "bob smith", "a_too_long_handle", "Admin", "ME"} {
+		assert.Error(t, validateHandle(handle), handle)
+	}
+}
+
+func TestHandles(t *testing.T) {
+	ts := newTestServer(t)
+
+	t.Run("Signup With Handle", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users", "", map[string]string{"email": "a@example.com", "password": "password", "handle": "Alice"})
+		require.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "Alice", decode[map[string]any](t, rec)["handle"])
+	})
+
+	t.Run("Taken Case Insensitively", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users", "", map[string]string{"email": "b@example.com", "password": "password", "handle": "alice"})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), "handle already taken")
+	})
+
+	t.Run("Reserved", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users", "", map[string]string{"email": "b@example.com", "password": "password", "handle": "support"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Set Later", func(t *testing.T) {
+		user := ts.signup("c@example.com")
+
+		rec := ts.do(http.MethodPut, "/api/users", user.bearer(), map[string]string{"handle": "ALICE"})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		rec = ts.do(http.MethodPut, "/api/users", user.bearer(), map[string]string{"handle": "carol"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "carol", decode[map[string]any](t, rec)["handle"])
+	})
+}
+
+func TestGetProfile(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+
+	rec := ts.do(http.MethodPut, "/api/users", user.bearer(), map[string]string{
+		"handle":       "alice",
+		"display_name": "Alice",
+		"bio":          "Hello",
+		"avatar_url":   "https://example.com/alice.png",
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	t.Run("By Handle", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/users/ALICE", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		profile := decode[map[string]any](t, rec)
+		assert.Equal(t, user.ID, profile["id"])
+		assert.Equal(t, "alice", profile["handle"])
+		assert.Equal(t, "Alice", profile["display_name"])
+		assert.Equal(t, "Hello", profile["bio"])
+		assert.Equal(t, "https://example.com/alice.png", profile["avatar_url"])
+		assert.NotContains(t, profile, "email")
+		assert.NotContains(t, rec.Body.String(), "a@example.com")
+		assert.NotContains(t, rec.Body.String(), "password")
+	})
+
+	t.Run("By ID", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/users/"+user.ID, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "alice", decode[map[string]any](t, rec)["handle"])
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/users/nobody", "", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Clear Field", func(t *testing.T) {
+		rec := ts.do(http.MethodPut, "/api/users", user.bearer(), map[string]string{"bio": ""})
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "", decode[map[string]any](t, rec)["bio"])
+	})
+
+	t.Run("Invalid Fields", func(t *testing.T) {
+		for _, body := range []map[string]string{
+			{"avatar_url": "javascript:alert(1)"},
+			{"bio": strings.Repeat("x", maxBioLength+1)},
+			{"display_name": strings.Repeat("x", maxDisplayNameLength+1)},
+		} {
+			rec := ts.do(http.MethodPut, "/api/users", user.bearer(), body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+
+			// Fields are only validated for authenticated users.
+			rec = ts.do(http.MethodPut, "/api/users", "", body)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code, body)
+		}
+	})
+}
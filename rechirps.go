@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// quotableChirp returns the chirp with the given ID, or the chirp it
+// rechirps: sharing a rechirp shares the original. It returns pgx.ErrNoRows
+// for malformed or unknown IDs.
+func (cfg *apiConfig) quotableChirp(id string) (database.Chirp, error) {
+	chirpID := pgtype.UUID{}
+	err := chirpID.Scan(id)
+	if err != nil {
+		return database.Chirp{}, pgx.ErrNoRows
+	}
+
+	chirp, err := cfg.db.GetChirp(context.Background(), chirpID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if chirp.RechirpOf.Valid {
+		return cfg.db.GetChirp(context.Background(), chirp.RechirpOf)
+	}
+	return chirp, nil
+}
+
+func (cfg *apiConfig) handleRechirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	original, err := cfg.quotableChirp(r.PathValue("chirpID"))
+	if err == nil {
+		err = cfg.checkNotBlocked(context.Background(), userID, original)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting chirp from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	params := database.CreateRechirpParams{UserID: userID, RechirpOf: original.ID}
+	status := http.StatusCreated
+
+	rechirp, err := cfg.db.CreateRechirp(context.Background(), params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Already rechirped: return the existing rechirp.
+		status = http.StatusOK
+		rechirp, err = cfg.db.GetRechirp(context.Background(), database.GetRechirpParams(params))
+	}
+	if err != nil {
+		if isForeignKeyViolation(err) || errors.Is(err, pgx.ErrNoRows) {
+			// The original was deleted in the meantime.
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error creating rechirp: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	responses, err := cfg.chirpResponses(context.Background(), userID, []database.Chirp{rechirp})
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(responses[0])
+	if err != nil {
+		log.Printf("Error marshalling chirp struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleUndoRechirp(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	original, err := cfg.quotableChirp(r.PathValue("chirpID"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting chirp from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = cfg.db.DeleteRechirp(context.Background(), database.DeleteRechirpParams{UserID: userID, RechirpOf: original.ID})
+	if err != nil {
+		log.Printf("Error deleting rechirp: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
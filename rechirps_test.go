@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSharedChirp struct {
+	ID           string           `json:"id"`
+	Body         string           `json:"body"`
+	RechirpCount int64            `json:"rechirp_count"`
+	Original     *testSharedChirp `json:"original"`
+	Deleted      bool             `json:"deleted"`
+}
+
+func TestRechirp(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	carol := ts.signup("carol@example.com")
+	original := ts.createChirp(alice, "original")
+	path := "/api/chirps/" + original.ID + "/rechirp"
+
+	var rechirp testSharedChirp
+
+	t.Run("Created", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, path, bob.bearer(), nil)
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		rechirp = decode[testSharedChirp](t, rec)
+		require.NotNil(t, rechirp.Original)
+		assert.Equal(t, original.ID, rechirp.Original.ID)
+		assert.Equal(t, "original", rechirp.Original.Body)
+		assert.EqualValues(t, 1, rechirp.Original.RechirpCount)
+	})
+
+	t.Run("Twice", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, path, bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, rechirp.ID, decode[testSharedChirp](t, rec).ID)
+	})
+
+	t.Run("Rechirp Of Rechirp", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps/"+rechirp.ID+"/rechirp", carol.bearer(), nil)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, original.ID, decode[testSharedChirp](t, rec).Original.ID)
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+original.ID, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, 2, decode[testSharedChirp](t, rec).RechirpCount)
+	})
+
+	t.Run("Undo", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, "/api/chirps/"+rechirp.ID+"/rechirp", carol.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+original.ID, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.EqualValues(t, 1, decode[testSharedChirp](t, rec).RechirpCount)
+	})
+
+	t.Run("Unknown Chirp", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps/"+bob.ID+"/rechirp", bob.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestQuoteChirp(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	original := ts.createChirp(alice, "original")
+
+	rec := ts.do(http.MethodPost, "/api/chirps", bob.bearer(), map[string]string{"body": "so true", "quote_of": original.ID})
+	require.Equal(t, http.StatusCreated, rec.Code)
+	quote := decode[testSharedChirp](t, rec)
+	assert.Equal(t, "so true", quote.Body)
+	require.NotNil(t, quote.Original)
+	assert.Equal(t, "original", quote.Original.Body)
+
+	rec = ts.do(http.MethodPost, "/api/chirps", bob.bearer(), map[string]string{"body": "huh", "quote_of": bob.ID})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	t.Run("Original Deleted", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps/"+original.ID+"/rechirp", bob.bearer(), nil)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		rechirp := decode[testSharedChirp](t, rec)
+
+		rec = ts.do(http.MethodDelete, "/api/chirps/"+original.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+rechirp.ID, "", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+quote.ID, "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		got := decode[testSharedChirp](t, rec)
+		require.NotNil(t, got.Original)
+		assert.Equal(t, testSharedChirp{ID: original.ID, Deleted: true}, *got.Original)
+	})
+}
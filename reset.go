@@ -17,7 +17,16 @@ func (cfg *apiConfig) handlerReset(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	err = cfg.db.ResetFileserverHits(context.Background())
+	if err != nil {
+		log.Printf("Error resetting fileserver hits: %v\n", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	// Every server drops the hits it has not flushed yet when it receives the
+	// event; this one does so right away so the response is accurate.
 	cfg.fileserverHits.Store(0)
+	cfg.publishEvent(context.Background(), eventUsersReset, struct{}{})
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("Hits reset to 0"))
 }
@@ -8,21 +8,92 @@ func getRouter(apiCfg *apiConfig) *http.ServeMux {
 	fsHandler := apiCfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(apiCfg.filepathRoot))))
 
 	mux.Handle("/app/", fsHandler)
+	mux.HandleFunc("GET /media/{key}", apiCfg.handleGetMedia)
 
 	mux.HandleFunc("GET /api/chirps", apiCfg.handleGetChirps)
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.handleSearchChirps)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handleGetChirp)
 	mux.HandleFunc("POST /api/chirps", apiCfg.handleCreateChirp)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handleDeleteChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/like", apiCfg.handleLikeChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/like", apiCfg.handleUnlikeChirp)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/likes", apiCfg.handleGetChirpLikes)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/bookmark", apiCfg.handleBookmarkChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/bookmark", apiCfg.handleDeleteBookmark)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.handleGetThread)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", apiCfg.handleRechirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/rechirp", apiCfg.handleUndoRechirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/poll/votes", apiCfg.handleVotePoll)
+
+	mux.HandleFunc("GET /api/scheduled_chirps", apiCfg.handleGetScheduledChirps)
+	mux.HandleFunc("GET /api/scheduled_chirps/{scheduledID}", apiCfg.handleGetScheduledChirp)
+	mux.HandleFunc("PATCH /api/scheduled_chirps/{scheduledID}", apiCfg.handleUpdateScheduledChirp)
+	mux.HandleFunc("DELETE /api/scheduled_chirps/{scheduledID}", apiCfg.handleDeleteScheduledChirp)
+
+	mux.HandleFunc("POST /api/drafts", apiCfg.handleCreateDraft)
+	mux.HandleFunc("GET /api/drafts", apiCfg.handleGetDrafts)
+	mux.HandleFunc("GET /api/drafts/{draftID}", apiCfg.handleGetDraft)
+	mux.HandleFunc("PUT /api/drafts/{draftID}", apiCfg.handleUpdateDraft)
+	mux.HandleFunc("DELETE /api/drafts/{draftID}", apiCfg.handleDeleteDraft)
+	mux.HandleFunc("POST /api/drafts/{draftID}/publish", apiCfg.handlePublishDraft)
+
+	mux.HandleFunc("POST /api/media", apiCfg.handleUploadMedia)
+
+	mux.HandleFunc("POST /api/conversations", apiCfg.handleCreateConversation)
+	mux.HandleFunc("GET /api/conversations", apiCfg.handleGetConversations)
+	mux.HandleFunc("GET /api/conversations/unread", apiCfg.handleGetUnreadCount)
+	mux.HandleFunc("GET /api/conversations/{conversationID}", apiCfg.handleGetConversation)
+	mux.HandleFunc("GET /api/conversations/{conversationID}/messages", apiCfg.handleGetMessages)
+	mux.HandleFunc("POST /api/conversations/{conversationID}/messages", apiCfg.handleSendMessage)
+	mux.HandleFunc("POST /api/conversations/{conversationID}/read", apiCfg.handleMarkConversationRead)
+
+	mux.HandleFunc("GET /api/notifications", apiCfg.handleGetNotifications)
+	mux.HandleFunc("POST /api/notifications/read", apiCfg.handleMarkNotificationsRead)
+	mux.HandleFunc("GET /api/notifications/unread", apiCfg.handleGetUnreadNotifications)
+	mux.HandleFunc("GET /api/notifications/preferences", apiCfg.handleGetNotificationPreferences)
+	mux.HandleFunc("PUT /api/notifications/preferences", apiCfg.handleUpdateNotificationPreferences)
+
+	mux.HandleFunc("POST /api/lists", apiCfg.handleCreateList)
+	mux.HandleFunc("GET /api/lists/{listID}", apiCfg.handleGetList)
+	mux.HandleFunc("PATCH /api/lists/{listID}", apiCfg.handleUpdateList)
+	mux.HandleFunc("DELETE /api/lists/{listID}", apiCfg.handleDeleteList)
+	mux.HandleFunc("GET /api/lists/{listID}/chirps", apiCfg.handleGetListChirps)
+	mux.HandleFunc("GET /api/lists/{listID}/members", apiCfg.handleGetListMembers)
+	mux.HandleFunc("PUT /api/lists/{listID}/members/{user}", apiCfg.handleAddListMember)
+	mux.HandleFunc("DELETE /api/lists/{listID}/members/{user}", apiCfg.handleRemoveListMember)
+
+	mux.HandleFunc("GET /api/timeline", apiCfg.handleGetTimeline)
+	mux.HandleFunc("GET /api/stream", apiCfg.handleStream)
+	mux.HandleFunc("GET /api/bookmarks", apiCfg.handleGetBookmarks)
+	mux.HandleFunc("GET /api/blocks", apiCfg.handleGetBlocks)
+	mux.HandleFunc("GET /api/mutes", apiCfg.handleGetMutes)
+
+	mux.HandleFunc("GET /api/hashtags", apiCfg.handleGetHashtags)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.handleGetHashtagChirps)
 
 	mux.HandleFunc("POST /api/polka/webhooks", apiCfg.handleUpgradeUser)
 
 	mux.HandleFunc("POST /api/users", apiCfg.handleCreateUser)
 	mux.HandleFunc("PUT /api/users", apiCfg.handleUpdateUser)
+	mux.HandleFunc("PUT /api/users/me/pinned", apiCfg.handlePinChirp)
+	mux.HandleFunc("DELETE /api/users/me/pinned", apiCfg.handleUnpinChirp)
+	mux.HandleFunc("GET /api/users/{user}", apiCfg.handleGetProfile)
+	mux.HandleFunc("POST /api/users/{user}/follow", apiCfg.handleFollowUser)
+	mux.HandleFunc("DELETE /api/users/{user}/follow", apiCfg.handleUnfollowUser)
+	mux.HandleFunc("GET /api/users/{user}/followers", apiCfg.handleGetFollowers)
+	mux.HandleFunc("GET /api/users/{user}/following", apiCfg.handleGetFollowing)
+	mux.HandleFunc("GET /api/users/{user}/likes", apiCfg.handleGetUserLikes)
+	mux.HandleFunc("GET /api/users/{user}/lists", apiCfg.handleGetUserLists)
+	mux.HandleFunc("POST /api/users/{user}/block", apiCfg.handleBlockUser)
+	mux.HandleFunc("DELETE /api/users/{user}/block", apiCfg.handleUnblockUser)
+	mux.HandleFunc("POST /api/users/{user}/mute", apiCfg.handleMuteUser)
+	mux.HandleFunc("DELETE /api/users/{user}/mute", apiCfg.handleUnmuteUser)
 	mux.HandleFunc("POST /api/login", apiCfg.handleAuthenticateUser)
 	mux.HandleFunc("POST /api/refresh", apiCfg.handleRefreshToken)
 	mux.HandleFunc("POST /api/revoke", apiCfg.handleRevokeRefreshToken)
 
 	mux.HandleFunc("GET /admin/metrics", apiCfg.handlerMetrics)
+	mux.HandleFunc("GET /admin/db", apiCfg.handlerDBStats)
 	mux.HandleFunc("POST /admin/reset", apiCfg.handlerReset)
 
 	mux.HandleFunc("POST /api/validate_chirp", handlerValidateChirp)
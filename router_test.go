@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chtozamm/chirpy/internal/blob"
+	"github.com/chtozamm/chirpy/internal/eventbus"
+	"github.com/chtozamm/chirpy/internal/memstore"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAuthSecret           = "test_secret"
+	testMaxStreamSubscribers = 2
+)
+
+type testServer struct {
+	t     *testing.T
+	cfg   *apiConfig
+	store *memstore.Store
+	mux   http.Handler
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	store := memstore.New()
+	blobs, err := blob.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	cfg := &apiConfig{
+		db:           store,
+		blobs:        blobs,
+		filepathRoot: "./static",
+		platform:     "dev",
+		authSecret:   testAuthSecret,
+		polkaKey:     "test_polka_key",
+		stream:       newChirpStream(testMaxStreamSubscribers, streamReplaySize),
+		events:       eventbus.NewLocal(),
+	}
+	cfg.events.Subscribe(cfg.handleEvent)
+
+	return &testServer{t: t, cfg: cfg, store: store, mux: getRouter(cfg)}
+}
+
+// do performs a request against the router. A non-empty authorization is sent
+// as the Authorization header verbatim, and a non-nil body is encoded as JSON.
+func (ts *testServer) do(method, path, authorization string, body any) *httptest.ResponseRecorder {
+	ts.t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(ts.t, json.NewEncoder(&buf).Encode(body))
+	}
+
+	req := httptest.NewRequest(method, path, &buf)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	rec := httptest.NewRecorder()
+	ts.mux.ServeHTTP(rec, req)
+	return rec
+}
+
+type testUser struct {
+	ID           string `json:"id"`
+	Email        string `json:"email"`
+	IsChirpyRed  bool   `json:"is_chirpy_red"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (u testUser) bearer() string {
+	return "Bearer " + u.Token
+}
+
+func (ts *testServer) userID(u testUser) pgtype.UUID {
+	ts.t.Helper()
+
+	id := pgtype.UUID{}
+	require.NoError(ts.t, id.Scan(u.ID))
+	return id
+}
+
+// signup creates a user and logs them in.
+func (ts *testServer) signup(email string) testUser {
+	ts.t.Helper()
+
+	credentials := map[string]string{"email": email, "password": "password"}
+
+	rec := ts.do(http.MethodPost, "/api/users", "", credentials)
+	require.Equal(ts.t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec = ts.do(http.MethodPost, "/api/login", "", credentials)
+	require.Equal(ts.t, http.StatusOK, rec.Code, rec.Body.String())
+
+	return decode[testUser](ts.t, rec)
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &v), rec.Body.String())
+	return v
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// maxScheduleAhead is how far in the future a chirp can be scheduled.
+	maxScheduleAhead = 365 * 24 * time.Hour
+	publishBatchSize = 100
+)
+
+// validatePublishAt checks the publishing time of a scheduled chirp. The
+// error is meant for the client.
+func validatePublishAt(publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return errors.New("publish_at must be in the future")
+	}
+	if time.Until(publishAt) > maxScheduleAhead {
+		return errors.New("publish_at cannot be more than a year away")
+	}
+	return nil
+}
+
+func (cfg *apiConfig) createScheduledChirp(w http.ResponseWriter, userID pgtype.UUID, body string, publishAt time.Time) {
+	err := validatePublishAt(publishAt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scheduled, err := cfg.db.CreateScheduledChirp(context.Background(), database.CreateScheduledChirpParams{
+		Body:      body,
+		UserID:    userID,
+		PublishAt: pgtype.Timestamp{Time: publishAt.UTC(), Valid: true},
+	})
+	if err != nil {
+		log.Printf("Error creating scheduled chirp: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(scheduled)
+	if err != nil {
+		log.Printf("Error marshalling scheduled chirp struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleGetScheduledChirps(w http.ResponseWriter, r *http.Request) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if page.Desc {
+		http.Error(w, "scheduled chirps can only be sorted asc", http.StatusBadRequest)
+		return
+	}
+
+	scheduled, err := cfg.db.GetScheduledChirps(context.Background(), database.GetScheduledChirpsParams{
+		UserID:          userID,
+		CursorPublishAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting scheduled chirps from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	scheduled, next := trimPage(scheduled, page, func(chirp database.ScheduledChirp) pageCursor {
+		return newPageCursor(chirp.PublishAt, chirp.ID)
+	})
+
+	resp, err := json.Marshal(scheduled)
+	if err != nil {
+		log.Printf("Error marshalling scheduled chirps struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+// ownScheduledChirp looks up the scheduled chirp in the request path. Other
+// users' scheduled chirps and ones already published are not found. It
+// writes the error response itself when ok is false.
+func (cfg *apiConfig) ownScheduledChirp(w http.ResponseWriter, r *http.Request) (scheduled database.ScheduledChirp, ok bool) {
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return database.ScheduledChirp{}, false
+	}
+
+	id := pgtype.UUID{}
+	err = id.Scan(r.PathValue("scheduledID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return database.ScheduledChirp{}, false
+	}
+
+	scheduled, err = cfg.db.GetScheduledChirp(context.Background(), database.GetScheduledChirpParams{ID: id, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return database.ScheduledChirp{}, false
+		}
+		log.Printf("Error getting scheduled chirp from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return database.ScheduledChirp{}, false
+	}
+	return scheduled, true
+}
+
+func (cfg *apiConfig) handleGetScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	scheduled, ok := cfg.ownScheduledChirp(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := json.Marshal(scheduled)
+	if err != nil {
+		log.Printf("Error marshalling scheduled chirp struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleUpdateScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	scheduled, ok := cfg.ownScheduledChirp(w, r)
+	if !ok {
+		return
+	}
+
+	type parameters struct {
+		Body      *string    `json:"body"`
+		PublishAt *time.Time `json:"publish_at"`
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		log.Printf("Error decoding parameters: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if params.Body == nil && params.PublishAt == nil {
+		http.Error(w, "at least one of body or publish_at must be provided", http.StatusBadRequest)
+		return
+	}
+	if params.Body != nil {
+		if *params.Body == "" {
+			http.Error(w, "body cannot be empty", http.StatusBadRequest)
+			return
+		}
+		scheduled.Body = *params.Body
+	}
+	if params.PublishAt != nil {
+		err = validatePublishAt(*params.PublishAt)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		scheduled.PublishAt = pgtype.Timestamp{Time: params.PublishAt.UTC(), Valid: true}
+	}
+
+	// The chirp may have been published since it was looked up, in which
+	// case it is no longer found.
+	scheduled, err = cfg.db.UpdateScheduledChirp(context.Background(), database.UpdateScheduledChirpParams{
+		Body:      scheduled.Body,
+		PublishAt: scheduled.PublishAt,
+		ID:        scheduled.ID,
+		UserID:    scheduled.UserID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error updating scheduled chirp: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	resp, err := json.Marshal(scheduled)
+	if err != nil {
+		log.Printf("Error marshalling scheduled chirp struct: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(resp)
+}
+
+func (cfg *apiConfig) handleDeleteScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	scheduled, ok := cfg.ownScheduledChirp(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := cfg.db.DeleteScheduledChirp(context.Background(), database.DeleteScheduledChirpParams{
+		ID:     scheduled.ID,
+		UserID: scheduled.UserID,
+	})
+	if err != nil {
+		log.Printf("Error deleting scheduled chirp: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if deleted == 0 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// publishDueChirps publishes every scheduled chirp whose time has come. It
+// is safe to run on several servers at once: each chirp is published by
+// exactly one of them.
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) error {
+	for {
+		chirps, err := cfg.db.PublishDueChirps(ctx, publishBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, chirp := range chirps {
+			cfg.afterChirpCreated(ctx, chirp)
+		}
+		if len(chirps) < publishBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testScheduledChirp struct {
+	ID        string `json:"id"`
+	Body      string `json:"body"`
+	PublishAt string `json:"publish_at"`
+}
+
+func (ts *testServer) scheduleChirp(user testUser, body string, publishAt time.Time) testScheduledChirp {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]any{"body": body, "publish_at": publishAt})
+	require.Equal(ts.t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[testScheduledChirp](ts.t, rec)
+}
+
+func TestScheduleChirp(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	scheduled := ts.scheduleChirp(alice, "coming soon", time.Now().Add(time.Hour))
+
+	t.Run("Not Published Yet", func(t *testing.T) {
+		assert.Empty(t, ts.chirpIDs(t, alice, "/api/chirps"))
+		assert.Empty(t, ts.chirpIDs(t, alice, "/api/chirps?author_id="+alice.ID))
+		rec := ts.do(http.MethodGet, "/api/chirps/"+scheduled.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Past Or Far Future", func(t *testing.T) {
+		for _, publishAt := range []time.Time{time.Now().Add(-time.Minute), time.Now().Add(2 * maxScheduleAhead)} {
+			rec := ts.do(http.MethodPost, "/api/chirps", alice.bearer(), map[string]any{"body": "when?", "publish_at": publishAt})
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("Body Only", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/chirps", alice.bearer(), map[string]any{
+			"body":        "a reply",
+			"in_reply_to": scheduled.ID,
+			"publish_at":  time.Now().Add(time.Hour),
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		later := ts.scheduleChirp(alice, "later", time.Now().Add(2*time.Hour))
+
+		rec := ts.do(http.MethodGet, "/api/scheduled_chirps", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		list := decode[[]testScheduledChirp](t, rec)
+		require.Len(t, list, 2)
+		assert.Equal(t, scheduled.ID, list[0].ID)
+		assert.Equal(t, later.ID, list[1].ID)
+
+		rec = ts.do(http.MethodGet, "/api/scheduled_chirps", bob.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, decode[[]testScheduledChirp](t, rec))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		publishAt := time.Now().Add(3 * time.Hour).UTC().Truncate(time.Second)
+		rec := ts.do(http.MethodPatch, "/api/scheduled_chirps/"+scheduled.ID, alice.bearer(), map[string]any{"publish_at": publishAt})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		updated := decode[testScheduledChirp](t, rec)
+		assert.Equal(t, "coming soon", updated.Body)
+		assert.Equal(t, publishAt.Format("2006-01-02T15:04:05"), updated.PublishAt)
+
+		rec = ts.do(http.MethodPatch, "/api/scheduled_chirps/"+scheduled.ID, alice.bearer(), map[string]any{"body": ""})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Owner Only", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodPatch, http.MethodDelete} {
+			rec := ts.do(method, "/api/scheduled_chirps/"+scheduled.ID, bob.bearer(), map[string]string{"body": "mine"})
+			assert.Equal(t, http.StatusNotFound, rec.Code, method)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, "/api/scheduled_chirps/"+scheduled.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/scheduled_chirps/"+scheduled.ID, alice.bearer(), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestPublishDueChirps(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+	future := ts.scheduleChirp(user, "not yet", time.Now().Add(time.Hour))
+
+	// Chirps cannot be scheduled in the past through the API.
+	due, err := ts.store.CreateScheduledChirp(context.Background(), database.CreateScheduledChirpParams{
+		Body:      "now #launch",
+		UserID:    ts.userID(user),
+		PublishAt: pgtype.Timestamp{Time: time.Now().Add(-time.Second).UTC(), Valid: true},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, ts.cfg.publishDueChirps(context.Background()))
+
+	dueID := due.ID.String()
+	assert.Equal(t, []string{dueID}, ts.chirpIDs(t, user, "/api/chirps"))
+	assert.Equal(t, []string{dueID}, ts.chirpIDs(t, user, "/api/hashtags/launch/chirps"))
+
+	rec := ts.do(http.MethodGet, "/api/scheduled_chirps/"+dueID, user.bearer(), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "published chirps are no longer scheduled")
+	rec = ts.do(http.MethodGet, "/api/scheduled_chirps/"+future.ID, user.bearer(), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
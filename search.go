@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type searchResult struct {
+	chirpResponse
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+func (cfg *apiConfig) handleSearchChirps(w http.ResponseWriter, r *http.Request) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+
+	tsquery, err := buildTSQuery(query.Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := parsePageRequest(r, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !page.Desc {
+		http.Error(w, "search results are always sorted newest or most relevant first", http.StatusBadRequest)
+		return
+	}
+
+	params := database.SearchChirpsParams{
+		Query:           tsquery,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		ViewerID:        viewer,
+		PageSize:        page.QueryLimit(),
+	}
+
+	if authorID := query.Get("author_id"); authorID != "" {
+		err = params.AuthorID.Scan(authorID)
+		if err != nil {
+			http.Error(w, "invalid author_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	params.Since, err = parseSearchTime(query.Get("since"))
+	if err != nil {
+		http.Error(w, "invalid since, expected RFC 3339 or YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+	params.Until, err = parseSearchTime(query.Get("until"))
+	if err != nil {
+		http.Error(w, "invalid until, expected RFC 3339 or YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	var results []searchResult
+
+	switch query.Get("order") {
+	case "", "relevance":
+		cursorRank := page.Cursor.Rank
+		if query.Get("cursor") == "" {
+			cursorRank = math.MaxFloat32
+		}
+
+		var rows []database.SearchChirpsByRankRow
+		rows, err = cfg.db.SearchChirpsByRank(context.Background(), database.SearchChirpsByRankParams{
+			Query:           params.Query,
+			AuthorID:        params.AuthorID,
+			Since:           params.Since,
+			Until:           params.Until,
+			CursorRank:      cursorRank,
+			CursorCreatedAt: params.CursorCreatedAt,
+			CursorID:        params.CursorID,
+			ViewerID:        params.ViewerID,
+			PageSize:        params.PageSize,
+		})
+		for _, row := range rows {
+			results = append(results, searchResult{chirpResponse: chirpResponse{Chirp: row.Chirp}, Rank: row.Rank, Snippet: row.Snippet})
+		}
+	case "recent":
+		var rows []database.SearchChirpsRow
+		rows, err = cfg.db.SearchChirps(context.Background(), params)
+		for _, row := range rows {
+			results = append(results, searchResult{chirpResponse: chirpResponse{Chirp: row.Chirp}, Rank: row.Rank, Snippet: row.Snippet})
+		}
+	default:
+		http.Error(w, "order must be relevance or recent", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error searching chirps: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	results, next := trimPage(results, page, func(result searchResult) pageCursor {
+		cursor := chirpCursor(result.Chirp)
+		cursor.Rank = result.Rank
+		return cursor
+	})
+
+	chirps := make([]database.Chirp, len(results))
+	for i, result := range results {
+		chirps[i] = result.Chirp
+	}
+	responses, err := cfg.chirpResponses(context.Background(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	for i := range results {
+		results[i].chirpResponse = responses[i]
+	}
+
+	resp, err := json.Marshal(results)
+	if err != nil {
+		log.Printf("Error marshalling search results: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+// parseSearchTime parses an optional RFC 3339 timestamp or calendar date.
+func parseSearchTime(value string) (pgtype.Timestamp, error) {
+	if value == "" {
+		return pgtype.Timestamp{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, value)
+		if err != nil {
+			return pgtype.Timestamp{}, err
+		}
+	}
+
+	return pgtype.Timestamp{Time: t.UTC(), Valid: true}, nil
+}
+
+// buildTSQuery turns a search box query into a to_tsquery expression. Terms
+// are ANDed together and may be:
+//
+//	word       contains word
+//	word*      contains a word starting with word
+//	"a b c"    contains the phrase a b c
+//	-word      does not contain word
+//
+// Everything but letters and digits is treated as a word separator, so the
+// result never contains tsquery syntax supplied by the user.
+func buildTSQuery(q string) (string, error) {
+	var terms []string
+	positive := false
+
+	for len(q) > 0 {
+		q = strings.TrimLeftFunc(q, unicode.IsSpace)
+		if q == "" {
+			break
+		}
+
+		var token string
+		if q[0] == '"' {
+			end := strings.IndexByte(q[1:], '"')
+			if end < 0 {
+				token, q = q[1:], ""
+			} else {
+				token, q = q[1:end+1], q[end+2:]
+			}
+			if words := tsqueryWords(token); len(words) > 0 {
+				terms = append(terms, tsqueryPhrase(words))
+				positive = true
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(q, unicode.IsSpace)
+		if end < 0 {
+			end = len(q)
+		}
+		token, q = q[:end], q[end:]
+
+		negate := strings.HasPrefix(token, "-")
+		prefix := strings.HasSuffix(token, "*")
+		words := tsqueryWords(token)
+		if len(words) == 0 {
+			continue
+		}
+
+		term := tsqueryPhrase(words)
+		switch {
+		case negate:
+			term = "!" + term
+		case prefix && len(words) == 1:
+			term += ":*"
+			positive = true
+		default:
+			positive = true
+		}
+		terms = append(terms, term)
+	}
+
+	if !positive {
+		return "", errors.New("q must contain at least one word to search for")
+	}
+	return strings.Join(terms, " & "), nil
+}
+
+func tsqueryWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func tsqueryPhrase(words []string) string {
+	if len(words) == 1 {
+		return words[0]
+	}
+	return "(" + strings.Join(words, " <-> ") + ")"
+}
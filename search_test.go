@@ -0,0 +1,122 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Single Word", "Hello", "hello"},
+		{"Multiple Words", "hello world", "hello & world"},
+		{"Phrase", `"hello big world"`, "(hello <-> big <-> world)"},
+		{"Prefix", "chir*", "chir:*"},
+		{"Negation", "hello -world", "hello & !world"},
+		{"Syntax Is Stripped", "a&b | c:*", "(a <-> b) & c:*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildTSQuery(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("Only Negations", func(t *testing.T) {
+		_, err := buildTSQuery("-hello")
+		assert.Error(t, err)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		_, err := buildTSQuery("  !! ")
+		assert.Error(t, err)
+	})
+}
+
+func TestSearchChirps(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	chirpy := ts.createChirp(alice, "Chirpy is a great place to chirp")
+	outdoors := ts.createChirp(alice, "The great outdoors")
+	both := ts.createChirp(bob, "A great chirp about great chirps")
+
+	type result struct {
+		ID      string  `json:"id"`
+		Rank    float32 `json:"rank"`
+		Snippet string  `json:"snippet"`
+	}
+
+	search := func(t *testing.T, params url.Values) []result {
+		rec := ts.do(http.MethodGet, "/api/chirps/search?"+params.Encode(), "", nil)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		return decode[[]result](t, rec)
+	}
+
+	t.Run("Phrase", func(t *testing.T) {
+		results := search(t, url.Values{"q": {`"great place"`}})
+		require.Len(t, results, 1)
+		assert.Equal(t, chirpy.ID, results[0].ID)
+		assert.Equal(t, "Chirpy is a <mark>great</mark> <mark>place</mark> to chirp", results[0].Snippet)
+	})
+
+	t.Run("Snippets Are Escaped", func(t *testing.T) {
+		markup := ts.createChirp(bob, `<img src=x onerror="alert(1)"> & escape`)
+		results := search(t, url.Values{"q": {"escape"}})
+		require.Len(t, results, 1)
+		assert.Equal(t, markup.ID, results[0].ID)
+		assert.Equal(t, "&lt;img src=x onerror=&quot;alert(1)&quot;&gt; &amp; <mark>escape</mark>", results[0].Snippet)
+	})
+
+	t.Run("Prefix And Relevance", func(t *testing.T) {
+		results := search(t, url.Values{"q": {"great chirp*"}})
+		require.Len(t, results, 2)
+		assert.Equal(t, both.ID, results[0].ID)
+	})
+
+	t.Run("Author Filter", func(t *testing.T) {
+		results := search(t, url.Values{"q": {"great"}, "author_id": {bob.ID}})
+		require.Len(t, results, 1)
+		assert.Equal(t, both.ID, results[0].ID)
+	})
+
+	t.Run("Date Filter", func(t *testing.T) {
+		assert.Empty(t, search(t, url.Values{"q": {"great"}, "until": {"2000-01-01"}}))
+		assert.Len(t, search(t, url.Values{"q": {"great"}, "since": {"2000-01-01"}}), 3)
+	})
+
+	t.Run("Paginated By Rank", func(t *testing.T) {
+		var ids []string
+		path := "/api/chirps/search?" + url.Values{"q": {"great"}, "limit": {"1"}}.Encode()
+		for path != "" {
+			rec := ts.do(http.MethodGet, path, "", nil)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			for _, r := range decode[[]result](t, rec) {
+				ids = append(ids, r.ID)
+			}
+
+			path = ""
+			if cursor := rec.Header().Get("X-Next-Cursor"); cursor != "" {
+				path = "/api/chirps/search?" + url.Values{"q": {"great"}, "limit": {"1"}, "cursor": {cursor}}.Encode()
+			}
+		}
+
+		// Ties in rank are broken by recency.
+		assert.Equal(t, []string{both.ID, outdoors.ID, chirpy.ID}, ids)
+	})
+
+	t.Run("Missing Query", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps/search", "", nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
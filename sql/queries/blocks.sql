@@ -0,0 +1,54 @@
+-- name: CreateBlock :execrows
+-- Blocking someone also removes the follows between the two users.
+WITH unfollowed AS (
+	DELETE FROM follows
+	WHERE (follower_id = sqlc.arg(blocker_id) AND followee_id = sqlc.arg(blocked_id))
+		OR (follower_id = sqlc.arg(blocked_id) AND followee_id = sqlc.arg(blocker_id))
+)
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES (sqlc.arg(blocker_id), sqlc.arg(blocked_id), NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBlock :execrows
+DELETE FROM blocks WHERE blocker_id = sqlc.arg(blocker_id) AND blocked_id = sqlc.arg(blocked_id);
+
+-- name: GetBlocks :many
+SELECT sqlc.embed(users), blocks.created_at AS blocked_at FROM blocks
+JOIN users ON users.id = blocks.blocked_id
+WHERE blocks.blocker_id = sqlc.arg(user_id)
+	AND (blocks.created_at, blocks.blocked_id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY blocks.created_at DESC, blocks.blocked_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: IsBlocked :one
+-- Reports whether either user has blocked the other.
+SELECT EXISTS (
+	SELECT 1 FROM blocks
+	WHERE (blocker_id = sqlc.arg(user_id) AND blocked_id = sqlc.arg(other_id))
+		OR (blocker_id = sqlc.arg(other_id) AND blocked_id = sqlc.arg(user_id))
+) AS blocked;
+
+-- name: CreateMute :execrows
+INSERT INTO mutes (muter_id, muted_id, created_at)
+VALUES (sqlc.arg(muter_id), sqlc.arg(muted_id), NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteMute :execrows
+DELETE FROM mutes WHERE muter_id = sqlc.arg(muter_id) AND muted_id = sqlc.arg(muted_id);
+
+-- name: GetMutes :many
+SELECT sqlc.embed(users), mutes.created_at AS muted_at FROM mutes
+JOIN users ON users.id = mutes.muted_id
+WHERE mutes.muter_id = sqlc.arg(user_id)
+	AND (mutes.created_at, mutes.muted_id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY mutes.created_at DESC, mutes.muted_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetAllHiddenUserIDs :many
+-- Returns every user the viewer must not see.
+SELECT user_id::uuid FROM hidden_users(sqlc.arg(viewer_id)::uuid);
+
+-- name: GetHiddenUserIDs :many
+-- Returns which of the given users the viewer must not see.
+SELECT user_id::uuid FROM hidden_users(sqlc.arg(viewer_id)::uuid)
+WHERE user_id = ANY(sqlc.arg(user_ids)::uuid[]);
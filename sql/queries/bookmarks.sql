@@ -0,0 +1,21 @@
+-- name: CreateBookmark :execrows
+INSERT INTO bookmarks (user_id, chirp_id, created_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(chirp_id), NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBookmark :execrows
+DELETE FROM bookmarks WHERE user_id = sqlc.arg(user_id) AND chirp_id = sqlc.arg(chirp_id);
+
+-- name: GetBookmarks :many
+SELECT sqlc.embed(chirps), bookmarks.created_at AS bookmarked_at FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE bookmarks.user_id = sqlc.arg(user_id)
+	AND (bookmarks.created_at, bookmarks.chirp_id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND chirps.user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.arg(user_id)))
+	AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+ORDER BY bookmarks.created_at DESC, bookmarks.chirp_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetBookmarkedChirpIDs :many
+SELECT chirp_id FROM bookmarks
+WHERE user_id = sqlc.arg(user_id) AND chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]);
@@ -1,11 +1,16 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, quote_of, in_reply_to, conversation_id, ancestor_ids, expires_at)
 VALUES (
 	gen_random_uuid(),
 	NOW(),
 	NOW(),
 	$1,
-	$2
+	$2,
+	sqlc.narg(quote_of),
+	sqlc.narg(in_reply_to),
+	sqlc.narg(conversation_id),
+	sqlc.arg(ancestor_ids)::uuid[],
+	sqlc.narg(expires_at)
 )
 RETURNING *;
 
@@ -13,13 +18,149 @@ RETURNING *;
 DELETE FROM chirps;
 
 -- name: GetChirps :many
-SELECT * FROM chirps ORDER BY created_at ASC;
+SELECT * FROM chirps
+WHERE (created_at, id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+	AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetChirpsDesc :many
+SELECT * FROM chirps
+WHERE (created_at, id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+	AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
 
 -- name: GetChirpsFromAuthor :many
-SELECT * FROM chirps WHERE user_id = $1 ORDER BY created_at ASC;
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg(user_id)
+	AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+	AND (expires_at IS NULL OR expires_at > NOW())
+	AND id IS DISTINCT FROM (SELECT pinned_chirp_id FROM users WHERE users.id = sqlc.arg(user_id))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetChirpsFromAuthorDesc :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg(user_id)
+	AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+	AND (expires_at IS NULL OR expires_at > NOW())
+	AND id IS DISTINCT FROM (SELECT pinned_chirp_id FROM users WHERE users.id = sqlc.arg(user_id))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
 
 -- name: GetChirp :one
-SELECT * FROM chirps WHERE id = $1;
+SELECT * FROM chirps WHERE id = $1 AND (expires_at IS NULL OR expires_at > NOW());
 
 -- name: DeleteChirp :exec
 DELETE FROM chirps WHERE id = $1;
+
+-- name: DeleteExpiredChirps :execrows
+-- Deletes up to max_results expired chirps. Their likes, bookmarks, hashtag
+-- links, polls and rechirps go with them through ON DELETE CASCADE, and their
+-- media is left to the media garbage collector.
+DELETE FROM chirps WHERE id IN (
+	SELECT id FROM chirps
+	WHERE expires_at <= NOW()
+	ORDER BY expires_at
+	LIMIT sqlc.arg(max_results)
+	FOR UPDATE SKIP LOCKED
+);
+
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps),
+	ts_rank(chirps.search_vector, query)::real AS rank,
+	ts_headline('english', html_escape(chirps.body), query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2')::text AS snippet
+FROM chirps, to_tsquery('english', sqlc.arg(query)) query
+WHERE chirps.search_vector @@ query
+	AND (sqlc.narg(author_id)::uuid IS NULL OR chirps.user_id = sqlc.narg(author_id))
+	AND (sqlc.narg(since)::timestamp IS NULL OR chirps.created_at >= sqlc.narg(since))
+	AND (sqlc.narg(until)::timestamp IS NULL OR chirps.created_at < sqlc.narg(until))
+	AND (chirps.created_at, chirps.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND chirps.user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+	AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: SearchChirpsByRank :many
+SELECT sqlc.embed(chirps),
+	ts_rank(chirps.search_vector, query)::real AS rank,
+	ts_headline('english', html_escape(chirps.body), query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2')::text AS snippet
+FROM chirps, to_tsquery('english', sqlc.arg(query)) query
+WHERE chirps.search_vector @@ query
+	AND (sqlc.narg(author_id)::uuid IS NULL OR chirps.user_id = sqlc.narg(author_id))
+	AND (sqlc.narg(since)::timestamp IS NULL OR chirps.created_at >= sqlc.narg(since))
+	AND (sqlc.narg(until)::timestamp IS NULL OR chirps.created_at < sqlc.narg(until))
+	AND (ts_rank(chirps.search_vector, query)::real, chirps.created_at, chirps.id)
+		< (sqlc.arg(cursor_rank)::real, sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND chirps.user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+	AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetTimeline :many
+-- Each author's newest chirps are read from chirps_user_id_created_at_idx
+-- separately, so the cost grows with the number of followed accounts times the
+-- page size instead of with their total number of chirps.
+WITH authors AS (
+	SELECT sqlc.arg(user_id)::uuid AS id
+	UNION ALL
+	SELECT followee_id FROM follows
+	WHERE follower_id = sqlc.arg(user_id)
+		AND followee_id NOT IN (SELECT user_id FROM hidden_users(sqlc.arg(user_id)))
+)
+SELECT timeline.* FROM authors
+CROSS JOIN LATERAL (
+	SELECT * FROM chirps
+	WHERE chirps.user_id = authors.id
+		AND (chirps.created_at, chirps.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+		AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+	ORDER BY chirps.created_at DESC, chirps.id DESC
+	LIMIT sqlc.arg(page_size)
+) AS timeline
+ORDER BY timeline.created_at DESC, timeline.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps WHERE id = ANY(sqlc.arg(ids)::uuid[]) AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: CreateRechirp :one
+-- Returns no rows if the user has already rechirped the chirp.
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of)
+VALUES (gen_random_uuid(), NOW(), NOW(), '', sqlc.arg(user_id), sqlc.arg(rechirp_of))
+ON CONFLICT (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL DO NOTHING
+RETURNING *;
+
+-- name: GetRechirp :one
+SELECT * FROM chirps WHERE user_id = sqlc.arg(user_id) AND rechirp_of = sqlc.arg(rechirp_of);
+
+-- name: DeleteRechirp :execrows
+DELETE FROM chirps WHERE user_id = sqlc.arg(user_id) AND rechirp_of = sqlc.arg(rechirp_of);
+
+-- name: GetRechirpCounts :many
+SELECT rechirp_of::uuid AS chirp_id, COUNT(*) AS rechirp_count FROM chirps
+WHERE rechirp_of = ANY(sqlc.arg(chirp_ids)::uuid[])
+GROUP BY rechirp_of;
+
+-- name: GetRechirpedChirpIDs :many
+SELECT rechirp_of::uuid AS chirp_id FROM chirps
+WHERE user_id = sqlc.arg(user_id) AND rechirp_of = ANY(sqlc.arg(chirp_ids)::uuid[]);
+
+-- name: GetThreadDescendants :many
+SELECT * FROM chirps
+WHERE ancestor_ids @> ARRAY[sqlc.arg(chirp_id)::uuid]
+	AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+	AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetReplyCounts :many
+SELECT in_reply_to::uuid AS chirp_id, COUNT(*) AS reply_count FROM chirps
+WHERE in_reply_to = ANY(sqlc.arg(chirp_ids)::uuid[])
+	AND (expires_at IS NULL OR expires_at > NOW())
+GROUP BY in_reply_to;
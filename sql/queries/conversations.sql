@@ -0,0 +1,102 @@
+-- name: CreateConversation :one
+-- Creates a conversation and its participants in a single statement.
+-- Returns no rows if a one-to-one conversation with the same direct_key
+-- already exists.
+WITH conversation AS (
+	INSERT INTO conversations (id, created_at, updated_at, direct_key)
+	VALUES (gen_random_uuid(), NOW(), NOW(), sqlc.narg(direct_key))
+	ON CONFLICT (direct_key) DO NOTHING
+	RETURNING *
+), participants AS (
+	INSERT INTO conversation_participants (conversation_id, user_id, created_at)
+	SELECT conversation.id, participant_ids.user_id, NOW()
+	FROM conversation, unnest(sqlc.arg(participant_ids)::uuid[]) AS participant_ids(user_id)
+)
+SELECT * FROM conversation;
+
+-- name: GetDirectConversation :one
+SELECT * FROM conversations WHERE direct_key = $1;
+
+-- name: GetConversation :one
+-- Returns the conversation only to its participants.
+SELECT conversations.* FROM conversations
+JOIN conversation_participants ON conversation_participants.conversation_id = conversations.id
+WHERE conversations.id = sqlc.arg(id) AND conversation_participants.user_id = sqlc.arg(user_id);
+
+-- name: GetConversations :many
+SELECT conversations.* FROM conversations
+JOIN conversation_participants ON conversation_participants.conversation_id = conversations.id
+WHERE conversation_participants.user_id = sqlc.arg(user_id)
+	AND (conversations.updated_at, conversations.id) < (sqlc.arg(cursor_updated_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY conversations.updated_at DESC, conversations.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetConversationParticipants :many
+SELECT conversation_participants.conversation_id, sqlc.embed(users), conversation_participants.last_read_at FROM conversation_participants
+JOIN users ON users.id = conversation_participants.user_id
+WHERE conversation_participants.conversation_id = ANY(sqlc.arg(conversation_ids)::uuid[])
+ORDER BY conversation_participants.created_at, conversation_participants.user_id;
+
+-- name: CreateMessage :one
+-- Sends a message as one of the participants and moves the conversation
+-- and the sender's read marker to it. Returns no rows if the sender is not a
+-- participant.
+WITH message AS (
+	INSERT INTO messages (id, created_at, conversation_id, user_id, body)
+	SELECT gen_random_uuid(), NOW(), conversation_id, user_id, sqlc.arg(body)
+	FROM conversation_participants
+	WHERE conversation_id = sqlc.arg(conversation_id) AND user_id = sqlc.arg(user_id)
+	RETURNING *
+), conversation AS (
+	UPDATE conversations SET updated_at = message.created_at
+	FROM message
+	WHERE conversations.id = message.conversation_id
+), read_marker AS (
+	UPDATE conversation_participants SET last_read_at = message.created_at
+	FROM message
+	WHERE conversation_participants.conversation_id = message.conversation_id
+		AND conversation_participants.user_id = message.user_id
+)
+SELECT * FROM message;
+
+-- name: GetMessages :many
+SELECT * FROM messages
+WHERE conversation_id = sqlc.arg(conversation_id)
+	AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: MarkConversationRead :execrows
+-- Moves the read marker to the newest message. It never moves back.
+UPDATE conversation_participants
+SET last_read_at = GREATEST(last_read_at, (SELECT MAX(created_at) FROM messages WHERE messages.conversation_id = sqlc.arg(conversation_id)))
+WHERE conversation_id = sqlc.arg(conversation_id) AND user_id = sqlc.arg(user_id);
+
+-- name: GetUnreadCounts :many
+-- Counts the messages of other participants after the read marker of
+-- user_id in each conversation. Conversations without unread messages are
+-- left out.
+SELECT messages.conversation_id, COUNT(*) AS unread_count FROM messages
+JOIN conversation_participants ON conversation_participants.conversation_id = messages.conversation_id
+WHERE conversation_participants.user_id = sqlc.arg(user_id)
+	AND messages.conversation_id = ANY(sqlc.arg(conversation_ids)::uuid[])
+	AND messages.user_id <> conversation_participants.user_id
+	AND (conversation_participants.last_read_at IS NULL OR messages.created_at > conversation_participants.last_read_at)
+GROUP BY messages.conversation_id;
+
+-- name: CountUnreadMessages :one
+SELECT COUNT(*) FROM messages
+JOIN conversation_participants ON conversation_participants.conversation_id = messages.conversation_id
+WHERE conversation_participants.user_id = $1
+	AND messages.user_id <> conversation_participants.user_id
+	AND (conversation_participants.last_read_at IS NULL OR messages.created_at > conversation_participants.last_read_at);
+
+-- name: IsBlockedInConversation :one
+-- Reports whether user_id has blocked or was blocked by another
+-- participant.
+SELECT EXISTS (
+	SELECT 1 FROM conversation_participants
+	JOIN blocks ON (blocks.blocker_id = sqlc.arg(user_id) AND blocks.blocked_id = conversation_participants.user_id)
+		OR (blocks.blocker_id = conversation_participants.user_id AND blocks.blocked_id = sqlc.arg(user_id))
+	WHERE conversation_participants.conversation_id = sqlc.arg(conversation_id)
+) AS blocked;
@@ -0,0 +1,53 @@
+-- name: CreateDraft :one
+INSERT INTO drafts (id, created_at, updated_at, user_id, body, quote_of, in_reply_to)
+VALUES (
+	gen_random_uuid(),
+	NOW(),
+	NOW(),
+	sqlc.arg(user_id),
+	sqlc.arg(body),
+	sqlc.narg(quote_of),
+	sqlc.narg(in_reply_to)
+)
+RETURNING *;
+
+-- name: GetDraft :one
+SELECT * FROM drafts WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: GetDrafts :many
+SELECT * FROM drafts
+WHERE user_id = sqlc.arg(user_id)
+	AND (updated_at, id) < (sqlc.arg(cursor_updated_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: UpdateDraft :one
+UPDATE drafts
+SET body = sqlc.arg(body), quote_of = sqlc.narg(quote_of), in_reply_to = sqlc.narg(in_reply_to), updated_at = NOW()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+-- name: DeleteDraft :execrows
+DELETE FROM drafts WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: PublishDraft :one
+-- Deletes the draft and creates the chirp validated from it in a single
+-- statement: a draft is published once, and returns no rows if it was
+-- deleted or published in the meantime.
+WITH draft AS (
+	DELETE FROM drafts WHERE drafts.id = sqlc.arg(draft_id) AND drafts.user_id = sqlc.arg(user_id)
+	RETURNING drafts.user_id
+)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, quote_of, in_reply_to, conversation_id, ancestor_ids)
+SELECT
+	gen_random_uuid(),
+	NOW(),
+	NOW(),
+	sqlc.arg(body),
+	draft.user_id,
+	sqlc.narg(quote_of),
+	sqlc.narg(in_reply_to),
+	sqlc.narg(conversation_id),
+	sqlc.arg(ancestor_ids)::uuid[]
+FROM draft
+RETURNING *;
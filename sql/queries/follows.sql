@@ -0,0 +1,30 @@
+-- name: CreateFollow :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES (sqlc.arg(follower_id), sqlc.arg(followee_id), NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFollow :execrows
+DELETE FROM follows WHERE follower_id = sqlc.arg(follower_id) AND followee_id = sqlc.arg(followee_id);
+
+-- name: GetFollowers :many
+SELECT sqlc.embed(users), follows.created_at AS followed_at FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg(user_id)
+	AND (follows.created_at, follows.follower_id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND users.id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+ORDER BY follows.created_at DESC, follows.follower_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetFollowing :many
+SELECT sqlc.embed(users), follows.created_at AS followed_at FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg(user_id)
+	AND (follows.created_at, follows.followee_id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND users.id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+ORDER BY follows.created_at DESC, follows.followee_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetFollowCounts :one
+SELECT
+	(SELECT COUNT(*) FROM follows WHERE followee_id = sqlc.arg(user_id)) AS followers_count,
+	(SELECT COUNT(*) FROM follows WHERE follower_id = sqlc.arg(user_id)) AS following_count;
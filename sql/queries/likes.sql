@@ -0,0 +1,35 @@
+-- name: CreateLike :execrows
+INSERT INTO likes (user_id, chirp_id, created_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(chirp_id), NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteLike :execrows
+DELETE FROM likes WHERE user_id = sqlc.arg(user_id) AND chirp_id = sqlc.arg(chirp_id);
+
+-- name: GetLikeCounts :many
+SELECT chirp_id, COUNT(*) AS like_count FROM likes
+WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+GROUP BY chirp_id;
+
+-- name: GetLikedChirpIDs :many
+SELECT chirp_id FROM likes
+WHERE user_id = sqlc.arg(user_id) AND chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]);
+
+-- name: GetChirpLikes :many
+SELECT sqlc.embed(users), likes.created_at AS liked_at FROM likes
+JOIN users ON users.id = likes.user_id
+WHERE likes.chirp_id = sqlc.arg(chirp_id)
+	AND (likes.created_at, likes.user_id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND users.id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+ORDER BY likes.created_at DESC, likes.user_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetUserLikes :many
+SELECT sqlc.embed(chirps), likes.created_at AS liked_at FROM likes
+JOIN chirps ON chirps.id = likes.chirp_id
+WHERE likes.user_id = sqlc.arg(user_id)
+	AND (likes.created_at, likes.chirp_id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND chirps.user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+	AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+ORDER BY likes.created_at DESC, likes.chirp_id DESC
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,79 @@
+-- name: CreateList :one
+INSERT INTO lists (id, created_at, updated_at, user_id, name, description, is_private)
+VALUES (
+	gen_random_uuid(),
+	NOW(),
+	NOW(),
+	sqlc.arg(user_id),
+	sqlc.arg(name),
+	sqlc.arg(description),
+	sqlc.arg(is_private)
+)
+RETURNING *;
+
+-- name: GetList :one
+SELECT * FROM lists WHERE id = $1;
+
+-- name: GetUserLists :many
+-- Private lists are only returned to their owner.
+SELECT * FROM lists
+WHERE user_id = sqlc.arg(user_id)
+	AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND (NOT is_private OR user_id = sqlc.narg(viewer_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: UpdateList :one
+UPDATE lists
+SET name = sqlc.arg(name), description = sqlc.arg(description), is_private = sqlc.arg(is_private), updated_at = NOW()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+-- name: DeleteList :execrows
+DELETE FROM lists WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: LockList :exec
+-- Serializes changes to the members of a list, such as checking its size
+-- before adding a member, until the transaction ends.
+SELECT id FROM lists WHERE id = $1 FOR UPDATE;
+
+-- name: AddListMember :execrows
+INSERT INTO list_members (list_id, user_id, created_at)
+VALUES (sqlc.arg(list_id), sqlc.arg(user_id), NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveListMember :execrows
+DELETE FROM list_members WHERE list_id = sqlc.arg(list_id) AND user_id = sqlc.arg(user_id);
+
+-- name: IsListMember :one
+SELECT EXISTS (
+	SELECT 1 FROM list_members WHERE list_id = sqlc.arg(list_id) AND user_id = sqlc.arg(user_id)
+) AS member;
+
+-- name: CountListMembers :one
+SELECT COUNT(*) FROM list_members WHERE list_id = $1;
+
+-- name: GetListMembers :many
+SELECT sqlc.embed(users), list_members.created_at AS added_at FROM list_members
+JOIN users ON users.id = list_members.user_id
+WHERE list_members.list_id = sqlc.arg(list_id)
+	AND (list_members.created_at, list_members.user_id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY list_members.created_at DESC, list_members.user_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetListChirps :many
+-- Like GetTimeline, each member's newest chirps are read from
+-- chirps_user_id_created_at_idx separately.
+SELECT timeline.* FROM list_members
+CROSS JOIN LATERAL (
+	SELECT * FROM chirps
+	WHERE chirps.user_id = list_members.user_id
+		AND (chirps.created_at, chirps.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+		AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+	ORDER BY chirps.created_at DESC, chirps.id DESC
+	LIMIT sqlc.arg(page_size)
+) AS timeline
+WHERE list_members.list_id = sqlc.arg(list_id)
+	AND list_members.user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+ORDER BY timeline.created_at DESC, timeline.id DESC
+LIMIT sqlc.arg(page_size);
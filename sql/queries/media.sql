@@ -0,0 +1,50 @@
+-- name: CreateMedia :one
+INSERT INTO media (id, created_at, user_id, content_type, width, height, blob_key, thumbnail_key)
+VALUES (
+	gen_random_uuid(),
+	NOW(),
+	sqlc.arg(user_id),
+	sqlc.arg(content_type),
+	sqlc.arg(width),
+	sqlc.arg(height),
+	sqlc.arg(blob_key),
+	sqlc.arg(thumbnail_key)
+)
+RETURNING *;
+
+-- name: GetMediaByIDs :many
+SELECT * FROM media WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: AttachMedia :execrows
+-- Attaches the user's unattached media to a chirp, in the order given.
+-- Media that is already attached, was attached to a since deleted chirp or
+-- belongs to someone else is skipped, so callers compare the row count
+-- with the number of IDs.
+UPDATE media
+SET chirp_id = sqlc.arg(chirp_id),
+	attached_at = NOW(),
+	position = attachments.ordinal::integer,
+	alt_text = attachments.alt_text
+FROM unnest(sqlc.arg(ids)::uuid[], sqlc.arg(alt_texts)::text[]) WITH ORDINALITY AS attachments(id, alt_text, ordinal)
+WHERE media.id = attachments.id
+	AND media.user_id = sqlc.arg(user_id)
+	AND media.chirp_id IS NULL
+	AND media.attached_at IS NULL;
+
+-- name: GetChirpMedia :many
+SELECT * FROM media
+WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: DeleteOrphanedMedia :many
+-- Deletes up to max_results media rows that no chirp uses: those whose chirp
+-- or uploader was deleted and those never attached that were uploaded before
+-- unattached_before. Their blobs are returned so the caller can delete them.
+DELETE FROM media WHERE id IN (
+	SELECT id FROM media
+	WHERE chirp_id IS NULL
+		AND (attached_at IS NOT NULL OR user_id IS NULL OR created_at < sqlc.arg(unattached_before)::timestamp)
+	LIMIT sqlc.arg(max_results)
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING blob_key, thumbnail_key;
@@ -0,0 +1,8 @@
+-- name: AddFileserverHits :exec
+UPDATE fileserver_hits SET hits = hits + $1;
+
+-- name: GetFileserverHits :one
+SELECT hits FROM fileserver_hits;
+
+-- name: ResetFileserverHits :exec
+UPDATE fileserver_hits SET hits = 0;
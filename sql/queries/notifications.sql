@@ -0,0 +1,66 @@
+-- name: CreateNotifications :execrows
+-- Notifies each of user_ids, except the actor, users who turned this type
+-- of notification off and users who blocked, were blocked by or muted the
+-- actor. Repeated events are only notified once.
+INSERT INTO notifications (id, created_at, user_id, actor_id, type, chirp_id)
+SELECT gen_random_uuid(), NOW(), recipients.user_id, sqlc.arg(actor_id), sqlc.arg(type), sqlc.narg(chirp_id)
+FROM unnest(sqlc.arg(user_ids)::uuid[]) AS recipients(user_id)
+WHERE recipients.user_id <> sqlc.arg(actor_id)
+	AND NOT EXISTS (
+		SELECT 1 FROM disabled_notifications
+		WHERE disabled_notifications.user_id = recipients.user_id AND disabled_notifications.type = sqlc.arg(type)
+	)
+	AND sqlc.arg(actor_id) NOT IN (SELECT user_id FROM hidden_users(recipients.user_id))
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteNotification :exec
+DELETE FROM notifications
+WHERE user_id = sqlc.arg(user_id) AND type = sqlc.arg(type) AND chirp_id IS NOT DISTINCT FROM sqlc.narg(chirp_id) AND actor_id = sqlc.arg(actor_id);
+
+-- name: GetNotificationGroups :many
+-- Groups the notifications of a user by type and chirp, so that the likes
+-- of a chirp or new followers are listed once, newest group first. Up to
+-- three of the latest actors are returned with each group.
+SELECT
+	type,
+	chirp_id,
+	COUNT(*) AS count,
+	COUNT(*) FILTER (WHERE read_at IS NULL) AS unread_count,
+	(array_agg(actor_id ORDER BY created_at DESC, id DESC))[1:3]::uuid[] AS actor_ids,
+	MAX(created_at)::timestamp AS latest_at,
+	(array_agg(id ORDER BY created_at DESC, id DESC))[1]::uuid AS latest_id
+FROM notifications
+WHERE user_id = sqlc.arg(user_id)
+	AND actor_id NOT IN (SELECT user_id FROM hidden_users(sqlc.arg(user_id)))
+GROUP BY type, chirp_id
+HAVING (MAX(created_at), (array_agg(id ORDER BY created_at DESC, id DESC))[1]) < (sqlc.arg(cursor_latest_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY latest_at DESC, latest_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: MarkNotificationsRead :execrows
+-- Marks every notification of a user read, or only the group of type and
+-- chirp_id when type is not NULL.
+UPDATE notifications SET read_at = NOW()
+WHERE user_id = sqlc.arg(user_id)
+	AND read_at IS NULL
+	AND (sqlc.narg(type)::text IS NULL OR (type = sqlc.narg(type) AND chirp_id IS NOT DISTINCT FROM sqlc.narg(chirp_id)));
+
+-- name: CountUnreadNotifications :one
+-- Counts the groups with unread notifications.
+SELECT COUNT(*) FROM (
+	SELECT DISTINCT type, chirp_id FROM notifications
+	WHERE user_id = $1
+		AND read_at IS NULL
+		AND actor_id NOT IN (SELECT user_id FROM hidden_users($1))
+) AS unread;
+
+-- name: GetDisabledNotificationTypes :many
+SELECT type FROM disabled_notifications WHERE user_id = $1 ORDER BY type;
+
+-- name: DisableNotificationType :exec
+INSERT INTO disabled_notifications (user_id, type)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: EnableNotificationType :exec
+DELETE FROM disabled_notifications WHERE user_id = $1 AND type = $2;
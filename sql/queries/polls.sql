@@ -0,0 +1,34 @@
+-- name: CreatePoll :one
+INSERT INTO polls (chirp_id, options, closes_at)
+VALUES (sqlc.arg(chirp_id), sqlc.arg(options)::text[], sqlc.arg(closes_at))
+RETURNING *;
+
+-- name: GetPoll :one
+SELECT * FROM polls WHERE chirp_id = sqlc.arg(chirp_id);
+
+-- name: GetPolls :many
+SELECT * FROM polls WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]);
+
+-- name: CreatePollVote :execrows
+-- Records a vote if the poll is still open and the option exists. Returns no
+-- rows if the user has already voted, so concurrent votes by the same user
+-- count once. Whether the poll is open is decided by the same statement
+-- that inserts the vote, so no vote lands after closing.
+INSERT INTO poll_votes (chirp_id, user_id, option_index, created_at)
+SELECT polls.chirp_id, sqlc.arg(user_id), sqlc.arg(option_index), NOW()
+FROM polls
+WHERE polls.chirp_id = sqlc.arg(chirp_id)
+	AND polls.closes_at > NOW()
+	AND sqlc.arg(option_index)::integer >= 0
+	AND sqlc.arg(option_index)::integer < cardinality(polls.options)
+ON CONFLICT DO NOTHING;
+
+-- name: GetPollVoteCounts :many
+SELECT chirp_id, option_index, COUNT(*) AS vote_count FROM poll_votes
+WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+GROUP BY chirp_id, option_index;
+
+-- name: GetPollVotes :many
+-- Returns the votes of a user in the given polls.
+SELECT * FROM poll_votes
+WHERE user_id = sqlc.arg(user_id) AND chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]);
@@ -0,0 +1,48 @@
+-- name: CreateScheduledChirp :one
+INSERT INTO scheduled_chirps (id, created_at, updated_at, body, user_id, publish_at)
+VALUES (
+	gen_random_uuid(),
+	NOW(),
+	NOW(),
+	sqlc.arg(body),
+	sqlc.arg(user_id),
+	sqlc.arg(publish_at)
+)
+RETURNING *;
+
+-- name: GetScheduledChirp :one
+SELECT * FROM scheduled_chirps WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: GetScheduledChirps :many
+SELECT * FROM scheduled_chirps
+WHERE user_id = sqlc.arg(user_id)
+	AND (publish_at, id) > (sqlc.arg(cursor_publish_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY publish_at ASC, id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: UpdateScheduledChirp :one
+UPDATE scheduled_chirps SET body = sqlc.arg(body), publish_at = sqlc.arg(publish_at), updated_at = NOW()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+-- name: DeleteScheduledChirp :execrows
+DELETE FROM scheduled_chirps WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id);
+
+-- name: PublishDueChirps :many
+-- Moves up to max_results scheduled chirps whose time has come into chirps
+-- and returns them. The move is a single statement, so a chirp is never
+-- both scheduled and published, and SKIP LOCKED lets several servers
+-- publish at once without picking the same rows.
+WITH due AS (
+	DELETE FROM scheduled_chirps WHERE id IN (
+		SELECT id FROM scheduled_chirps
+		WHERE publish_at <= NOW()
+		ORDER BY publish_at
+		LIMIT sqlc.arg(max_results)
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id, body, user_id
+)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+SELECT id, NOW(), NOW(), body, user_id FROM due
+RETURNING *;
@@ -0,0 +1,29 @@
+-- name: CreateChirpTags :exec
+WITH tag_ids AS (
+	INSERT INTO tags (id, created_at, name)
+	SELECT gen_random_uuid(), NOW(), name FROM unnest(sqlc.arg(names)::text[]) AS name
+	ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+	RETURNING id
+)
+INSERT INTO chirp_tags (chirp_id, tag_id, created_at)
+SELECT sqlc.arg(chirp_id)::uuid, id, sqlc.arg(created_at)::timestamp FROM tag_ids
+ON CONFLICT DO NOTHING;
+
+-- name: GetChirpsByTag :many
+SELECT chirps.* FROM chirp_tags
+JOIN tags ON tags.id = chirp_tags.tag_id
+JOIN chirps ON chirps.id = chirp_tags.chirp_id
+WHERE tags.name = sqlc.arg(name)
+	AND (chirp_tags.created_at, chirp_tags.chirp_id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+	AND chirps.user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid))
+	AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+ORDER BY chirp_tags.created_at DESC, chirp_tags.chirp_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetTagsByPrefix :many
+SELECT tags.name, COUNT(*) AS chirp_count FROM tags
+JOIN chirp_tags ON chirp_tags.tag_id = tags.id
+WHERE tags.name LIKE sqlc.arg(prefix)::text || '%'
+GROUP BY tags.name
+ORDER BY chirp_count DESC, tags.name ASC
+LIMIT sqlc.arg(max_results);
@@ -1,11 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
 VALUES (
 	gen_random_uuid(),
 	NOW(),
 	NOW(),
 	$1,
-	$2
+	$2,
+	$3
 )
 RETURNING *;
 
@@ -18,8 +19,42 @@ SELECT * FROM users WHERE email = $1;
 -- name: GetUserByID :one
 SELECT * FROM users WHERE id = $1;
 
+-- name: GetUserByHandle :one
+SELECT * FROM users WHERE lower(handle) = lower(sqlc.arg(handle));
+
+-- name: GetUsersByIDs :many
+SELECT * FROM users WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: GetUsersByHandles :many
+-- handles must be lowercase.
+SELECT * FROM users WHERE lower(handle) = ANY(sqlc.arg(handles)::text[]);
+
 -- name: UpdateUser :one
-UPDATE users SET email = $1, hashed_password = $2, updated_at = $3 WHERE id = $4 RETURNING *;
+UPDATE users SET email = $1, hashed_password = $2, handle = $3, display_name = $4, bio = $5, avatar_url = $6, updated_at = $7
+WHERE id = $8 RETURNING *;
 
 -- name: UpgradeUser :exec
 UPDATE users SET is_chirpy_red = TRUE, updated_at = $1 WHERE id = $2;
+
+-- name: PinChirp :execrows
+-- Pins a chirp to the profile of its author. Nothing is updated unless the
+-- chirp is one of the user's own, visible chirps and not a rechirp.
+UPDATE users SET pinned_chirp_id = sqlc.arg(chirp_id), updated_at = NOW()
+WHERE users.id = sqlc.arg(user_id)
+	AND EXISTS (
+		SELECT 1 FROM chirps
+		WHERE chirps.id = sqlc.arg(chirp_id)
+			AND chirps.user_id = sqlc.arg(user_id)
+			AND chirps.rechirp_of IS NULL
+			AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+	);
+
+-- name: UnpinChirp :exec
+UPDATE users SET pinned_chirp_id = NULL, updated_at = NOW() WHERE id = $1;
+
+-- name: GetPinnedChirp :one
+SELECT chirps.* FROM users
+JOIN chirps ON chirps.id = users.pinned_chirp_id
+WHERE users.id = sqlc.arg(user_id)
+	AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+	AND chirps.user_id NOT IN (SELECT user_id FROM hidden_users(sqlc.narg(viewer_id)::uuid));
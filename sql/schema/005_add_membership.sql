@@ -2,4 +2,4 @@
 ALTER TABLE users ADD COLUMN is_chirpy_red BOOL NOT NULL DEFAULT FALSE;
 
 -- +goose Down
-ALTER TABLE users DELETE COLUMN is_chirpy_red;
+ALTER TABLE users DROP COLUMN is_chirpy_red;
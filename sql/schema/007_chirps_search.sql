@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN search_vector TSVECTOR NOT NULL
+	GENERATED ALWAYS AS (to_tsvector('english', body)) STORED;
+CREATE INDEX chirps_search_vector_idx ON chirps USING GIN (search_vector);
+
+-- html_escape escapes text for use in HTML element content. Search snippets
+-- are built from the escaped body so that only the highlighting is markup;
+-- the default text search parser reads the entities as separate tokens, so
+-- the words around them still match.
+-- +goose StatementBegin
+CREATE FUNCTION html_escape(body TEXT) RETURNS TEXT
+LANGUAGE sql IMMUTABLE AS $$
+	SELECT replace(replace(replace(replace(body, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION html_escape(TEXT);
+DROP INDEX chirps_search_vector_idx;
+ALTER TABLE chirps DROP COLUMN search_vector;
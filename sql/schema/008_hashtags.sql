@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE tags(
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE INDEX tags_name_pattern_idx ON tags (name text_pattern_ops);
+
+CREATE TABLE chirp_tags(
+	chirp_id UUID NOT NULL,
+	tag_id UUID NOT NULL,
+	-- Copied from chirps so tag timelines can be paginated from the index.
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY(chirp_id, tag_id),
+	FOREIGN KEY(chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+	FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+CREATE INDEX chirp_tags_tag_id_created_at_idx ON chirp_tags (tag_id, created_at, chirp_id);
+
+-- +goose Down
+DROP TABLE chirp_tags;
+DROP TABLE tags;
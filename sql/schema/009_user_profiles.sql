@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN handle TEXT;
+ALTER TABLE users ADD COLUMN display_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar_url TEXT NOT NULL DEFAULT '';
+-- Handles keep the case they were chosen with but are unique regardless of it.
+CREATE UNIQUE INDEX users_handle_key ON users (lower(handle));
+
+-- +goose Down
+DROP INDEX users_handle_key;
+ALTER TABLE users DROP COLUMN avatar_url;
+ALTER TABLE users DROP COLUMN bio;
+ALTER TABLE users DROP COLUMN display_name;
+ALTER TABLE users DROP COLUMN handle;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE follows(
+	follower_id UUID NOT NULL,
+	followee_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY(follower_id, followee_id),
+	FOREIGN KEY(follower_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY(followee_id) REFERENCES users(id) ON DELETE CASCADE,
+	CONSTRAINT follows_no_self_follow CHECK (follower_id <> followee_id)
+);
+-- Follower and following lists are paginated newest first from these.
+CREATE INDEX follows_followee_id_created_at_idx ON follows (followee_id, created_at, follower_id);
+CREATE INDEX follows_follower_id_created_at_idx ON follows (follower_id, created_at, followee_id);
+
+-- +goose Down
+DROP TABLE follows;
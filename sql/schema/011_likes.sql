@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE likes(
+	user_id UUID NOT NULL,
+	chirp_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY(chirp_id, user_id),
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY(chirp_id) REFERENCES chirps(id) ON DELETE CASCADE
+);
+-- Like counts are counted from the primary key; these serve the paginated
+-- liked-by and liked-chirps lists.
+CREATE INDEX likes_chirp_id_created_at_idx ON likes (chirp_id, created_at, user_id);
+CREATE INDEX likes_user_id_created_at_idx ON likes (user_id, created_at, chirp_id);
+
+-- +goose Down
+DROP TABLE likes;
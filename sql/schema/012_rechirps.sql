@@ -0,0 +1,16 @@
+-- +goose Up
+-- A rechirp is a chirp with an empty body that reposts another one, and goes
+-- away with it.
+ALTER TABLE chirps ADD COLUMN rechirp_of UUID REFERENCES chirps(id) ON DELETE CASCADE;
+-- A quote keeps its own body. quote_of deliberately has no foreign key: when
+-- the quoted chirp is deleted the reference is kept so that it can be shown
+-- as deleted.
+ALTER TABLE chirps ADD COLUMN quote_of UUID;
+CREATE UNIQUE INDEX chirps_rechirp_key ON chirps (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL;
+CREATE INDEX chirps_rechirp_of_idx ON chirps (rechirp_of) WHERE rechirp_of IS NOT NULL;
+
+-- +goose Down
+DROP INDEX chirps_rechirp_of_idx;
+DROP INDEX chirps_rechirp_key;
+ALTER TABLE chirps DROP COLUMN quote_of;
+ALTER TABLE chirps DROP COLUMN rechirp_of;
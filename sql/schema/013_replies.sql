@@ -0,0 +1,18 @@
+-- +goose Up
+-- Like quote_of, in_reply_to has no foreign key so that replies to a deleted
+-- chirp keep their place in the thread.
+ALTER TABLE chirps ADD COLUMN in_reply_to UUID;
+-- The first chirp of the thread; NULL for chirps that are not replies.
+ALTER TABLE chirps ADD COLUMN conversation_id UUID;
+-- Every chirp between the root and this one, root first, so that a thread
+-- can be read in one query even when some of it has been deleted.
+ALTER TABLE chirps ADD COLUMN ancestor_ids UUID[] NOT NULL DEFAULT '{}';
+CREATE INDEX chirps_in_reply_to_idx ON chirps (in_reply_to) WHERE in_reply_to IS NOT NULL;
+CREATE INDEX chirps_ancestor_ids_idx ON chirps USING GIN (ancestor_ids);
+
+-- +goose Down
+DROP INDEX chirps_ancestor_ids_idx;
+DROP INDEX chirps_in_reply_to_idx;
+ALTER TABLE chirps DROP COLUMN ancestor_ids;
+ALTER TABLE chirps DROP COLUMN conversation_id;
+ALTER TABLE chirps DROP COLUMN in_reply_to;
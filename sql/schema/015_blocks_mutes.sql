@@ -0,0 +1,44 @@
+-- +goose Up
+CREATE TABLE blocks(
+	blocker_id UUID NOT NULL,
+	blocked_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY(blocker_id, blocked_id),
+	FOREIGN KEY(blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY(blocked_id) REFERENCES users(id) ON DELETE CASCADE,
+	CONSTRAINT blocks_no_self_block CHECK (blocker_id <> blocked_id)
+);
+CREATE INDEX blocks_blocker_id_created_at_idx ON blocks (blocker_id, created_at, blocked_id);
+-- Looks up who blocked a user when hiding their chirps from them.
+CREATE INDEX blocks_blocked_id_idx ON blocks (blocked_id);
+
+CREATE TABLE mutes(
+	muter_id UUID NOT NULL,
+	muted_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY(muter_id, muted_id),
+	FOREIGN KEY(muter_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY(muted_id) REFERENCES users(id) ON DELETE CASCADE,
+	CONSTRAINT mutes_no_self_mute CHECK (muter_id <> muted_id)
+);
+CREATE INDEX mutes_muter_id_created_at_idx ON mutes (muter_id, created_at, muted_id);
+
+-- hidden_users returns the users whose chirps viewer_id must not see: those
+-- blocked by or blocking viewer_id, and those viewer_id muted. It returns no
+-- rows for a NULL viewer. Being a single STABLE SQL statement, it is inlined
+-- into the queries that use it.
+-- +goose StatementBegin
+CREATE FUNCTION hidden_users(viewer_id UUID) RETURNS TABLE(user_id UUID)
+LANGUAGE sql STABLE AS $$
+	SELECT blocked_id FROM blocks WHERE blocker_id = viewer_id
+	UNION
+	SELECT blocker_id FROM blocks WHERE blocked_id = viewer_id
+	UNION
+	SELECT muted_id FROM mutes WHERE muter_id = viewer_id
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION hidden_users(UUID);
+DROP TABLE mutes;
+DROP TABLE blocks;
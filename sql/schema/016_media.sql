@@ -0,0 +1,26 @@
+-- +goose Up
+-- Media rows outlive their uploader and chirp so that the garbage collector
+-- can still find and delete their blobs: both references are set to NULL
+-- instead of cascading.
+CREATE TABLE media(
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+	chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+	-- Set once when the media is attached to a chirp, so that media of a
+	-- deleted chirp cannot be attached again.
+	attached_at TIMESTAMP,
+	position INTEGER NOT NULL DEFAULT 0,
+	alt_text TEXT NOT NULL DEFAULT '',
+	content_type TEXT NOT NULL,
+	width INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	blob_key TEXT NOT NULL,
+	thumbnail_key TEXT NOT NULL
+);
+CREATE INDEX media_chirp_id_idx ON media (chirp_id, position);
+-- Finds garbage: media never attached, or whose chirp was deleted.
+CREATE INDEX media_orphaned_idx ON media (created_at) WHERE chirp_id IS NULL;
+
+-- +goose Down
+DROP TABLE media;
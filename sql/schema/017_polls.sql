@@ -0,0 +1,27 @@
+-- +goose Up
+-- A poll belongs to a single chirp. Polls close by themselves: a poll is
+-- open while closes_at is in the future, so nothing needs to update it.
+CREATE TABLE polls(
+	chirp_id UUID PRIMARY KEY,
+	options TEXT[] NOT NULL,
+	closes_at TIMESTAMP NOT NULL,
+	FOREIGN KEY(chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+	CONSTRAINT polls_option_count CHECK (cardinality(options) BETWEEN 2 AND 4)
+);
+
+-- option_index is the zero-based position of the chosen option in
+-- polls.options. The primary key allows a single vote per user.
+CREATE TABLE poll_votes(
+	chirp_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	option_index INTEGER NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY(chirp_id, user_id),
+	FOREIGN KEY(chirp_id) REFERENCES polls(chirp_id) ON DELETE CASCADE,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX poll_votes_user_id_idx ON poll_votes (user_id);
+
+-- +goose Down
+DROP TABLE poll_votes;
+DROP TABLE polls;
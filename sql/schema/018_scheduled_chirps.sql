@@ -0,0 +1,17 @@
+-- +goose Up
+-- Scheduled chirps live apart from chirps so that no chirp query has to
+-- filter them out. Publishing moves a row into chirps under the same ID.
+CREATE TABLE scheduled_chirps(
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	body TEXT NOT NULL,
+	user_id UUID NOT NULL,
+	publish_at TIMESTAMP NOT NULL,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX scheduled_chirps_publish_at_idx ON scheduled_chirps (publish_at);
+CREATE INDEX scheduled_chirps_user_id_publish_at_idx ON scheduled_chirps (user_id, publish_at, id);
+
+-- +goose Down
+DROP TABLE scheduled_chirps;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Drafts are private to their author and live apart from chirps, so no
+-- chirp query can list them. quote_of and in_reply_to are not foreign keys:
+-- the chirps they refer to may be deleted before the draft is published,
+-- which publishing then reports.
+CREATE TABLE drafts(
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id UUID NOT NULL,
+	body TEXT NOT NULL,
+	quote_of UUID,
+	in_reply_to UUID,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX drafts_user_id_updated_at_idx ON drafts (user_id, updated_at, id);
+
+-- +goose Down
+DROP TABLE drafts;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Deleting the pinned chirp, including by the expired chirp reaper, unpins
+-- it.
+ALTER TABLE users ADD COLUMN pinned_chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN pinned_chirp_id;
@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE lists(
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	user_id UUID NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	-- Private lists are only visible to their owner.
+	is_private BOOLEAN NOT NULL DEFAULT FALSE,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX lists_user_id_created_at_idx ON lists (user_id, created_at, id);
+
+CREATE TABLE list_members(
+	list_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	PRIMARY KEY(list_id, user_id),
+	FOREIGN KEY(list_id) REFERENCES lists(id) ON DELETE CASCADE,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- Serves the paginated member list; the primary key serves list timelines.
+CREATE INDEX list_members_list_id_created_at_idx ON list_members (list_id, created_at, user_id);
+-- Finds the memberships to delete with a user.
+CREATE INDEX list_members_user_id_idx ON list_members (user_id);
+
+-- +goose Down
+DROP TABLE list_members;
+DROP TABLE lists;
@@ -0,0 +1,41 @@
+-- +goose Up
+CREATE TABLE conversations(
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	-- Time of the newest message, which orders the inbox.
+	updated_at TIMESTAMP NOT NULL,
+	-- The two participant IDs of a one-to-one conversation in a canonical
+	-- order, so that each pair has at most one. NULL for group conversations.
+	direct_key TEXT UNIQUE
+);
+
+CREATE TABLE conversation_participants(
+	conversation_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	-- Messages created up to this time have been read by the participant.
+	last_read_at TIMESTAMP,
+	PRIMARY KEY(conversation_id, user_id),
+	FOREIGN KEY(conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- Serves the inbox of a user and the deletion of their participations.
+CREATE INDEX conversation_participants_user_id_idx ON conversation_participants (user_id);
+
+CREATE TABLE messages(
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	conversation_id UUID NOT NULL,
+	user_id UUID NOT NULL,
+	body TEXT NOT NULL,
+	FOREIGN KEY(conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX messages_conversation_id_created_at_idx ON messages (conversation_id, created_at, id);
+-- Finds the messages to delete with a user.
+CREATE INDEX messages_user_id_idx ON messages (user_id);
+
+-- +goose Down
+DROP TABLE messages;
+DROP TABLE conversation_participants;
+DROP TABLE conversations;
@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE notifications(
+	id UUID PRIMARY KEY,
+	created_at TIMESTAMP NOT NULL,
+	-- The user being notified.
+	user_id UUID NOT NULL,
+	-- The user whose action triggered the notification.
+	actor_id UUID NOT NULL,
+	type TEXT NOT NULL,
+	-- The liked chirp, or the reply or chirp mentioning user_id. NULL for
+	-- follows.
+	chirp_id UUID,
+	read_at TIMESTAMP,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY(actor_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY(chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+	CONSTRAINT notifications_type_check CHECK (type IN ('follow', 'like', 'mention', 'reply')),
+	-- Repeated events, such as a user mentioned twice in one chirp, notify
+	-- once. Unliking or unfollowing deletes the notification, so liking or
+	-- following again notifies again. Also serves the grouping of the
+	-- notifications of a user.
+	CONSTRAINT notifications_event_key UNIQUE NULLS NOT DISTINCT (user_id, type, chirp_id, actor_id)
+);
+-- Find the notifications to delete with a user or chirp.
+CREATE INDEX notifications_actor_id_idx ON notifications (actor_id);
+CREATE INDEX notifications_chirp_id_idx ON notifications (chirp_id);
+
+-- Notification types a user turned off.
+CREATE TABLE disabled_notifications(
+	user_id UUID NOT NULL,
+	type TEXT NOT NULL,
+	PRIMARY KEY(user_id, type),
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+	CONSTRAINT disabled_notifications_type_check CHECK (type IN ('follow', 'like', 'mention', 'reply'))
+);
+
+-- +goose Down
+DROP TABLE disabled_notifications;
+DROP TABLE notifications;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- fileserver_hits has a single row counting the visits to /app through every
+-- server. Servers count visits in memory and add them here periodically.
+CREATE TABLE fileserver_hits(
+	id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+	hits BIGINT NOT NULL DEFAULT 0
+);
+INSERT INTO fileserver_hits DEFAULT VALUES;
+
+-- +goose Down
+DROP TABLE fileserver_hits;
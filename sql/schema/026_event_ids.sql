@@ -0,0 +1,7 @@
+-- +goose Up
+-- event_ids numbers the events servers publish to each other, which stream
+-- clients use to resume from any server.
+CREATE SEQUENCE event_ids;
+
+-- +goose Down
+DROP SEQUENCE event_ids;
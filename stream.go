@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	streamEventChirpCreated = "chirp_created"
+	streamEventChirpDeleted = "chirp_deleted"
+	streamEventUserCreated  = "user_created"
+	streamEventUserUpdated  = "user_updated"
+
+	// streamReplaySize is the number of recent events kept for clients that
+	// reconnect with a Last-Event-ID.
+	streamReplaySize = 256
+	// streamSubscriberBuffer is the number of events a subscriber may fall
+	// behind by before it is disconnected.
+	streamSubscriberBuffer  = 64
+	streamHeartbeatInterval = 15 * time.Second
+	// streamHiddenUsersRefreshInterval is how often the users hidden from a
+	// subscriber are reloaded, so a block or mute takes effect on open
+	// streams within it.
+	streamHiddenUsersRefreshInterval = time.Minute
+)
+
+// streamEvent is a server-sent event. UserID is the user the event is about,
+// or the author of the chirp it is about, and is used to filter the stream.
+// OriginalUserID is the author of the chirp a new chirp rechirps or quotes,
+// if any: subscribers who must not see them are sent UnavailableOriginalData,
+// in which the original is a tombstone.
+type streamEvent struct {
+	ID                      uint64
+	Type                    string
+	UserID                  pgtype.UUID
+	Data                    []byte
+	OriginalUserID          pgtype.UUID
+	UnavailableOriginalData []byte
+}
+
+// chirpStream fans chirp events out to the subscribers of GET /api/stream
+// and keeps the most recent of them for resuming.
+type chirpStream struct {
+	mu             sync.Mutex
+	replay         []streamEvent
+	replaySize     int
+	subscribers    map[chan streamEvent]struct{}
+	maxSubscribers int
+}
+
+// newChirpStream returns a stream that accepts up to maxSubscribers
+// concurrent subscribers and replays up to replaySize events.
+func newChirpStream(maxSubscribers, replaySize int) *chirpStream {
+	return &chirpStream{
+		replaySize:     replaySize,
+		subscribers:    make(map[chan streamEvent]struct{}),
+		maxSubscribers: maxSubscribers,
+	}
+}
+
+// publish sends an event to every subscriber. Its ID is that of the event
+// bus event it comes from, which is the same on every server, so clients can
+// resume from any of them. A subscriber that is too far behind to take it is
+// disconnected rather than blocking the publisher; the client can reconnect
+// and resume from the replay buffer.
+func (s *chirpStream) publish(event streamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.replay) == s.replaySize {
+		s.replay = append(s.replay[:0], s.replay[1:]...)
+	}
+	s.replay = append(s.replay, event)
+
+	for events := range s.subscribers {
+		select {
+		case events <- event:
+		default:
+			delete(s.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// subscribe registers a subscriber and returns its channel along with the
+// buffered events after the one with lastEventID. If that event is no longer
+// buffered, every buffered event is returned. Events are matched by position
+// rather than by comparing IDs, since the bus delivers events in the same
+// order to every server but not necessarily in the order of their IDs. ok is
+// false if there are already maxSubscribers subscribers.
+func (s *chirpStream) subscribe(lastEventID uint64, resume bool) (events chan streamEvent, missed []streamEvent, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.subscribers) >= s.maxSubscribers {
+		return nil, nil, false
+	}
+
+	if resume {
+		// i is -1 when the event is not buffered, so all of them are missed.
+		i := slices.IndexFunc(s.replay, func(event streamEvent) bool { return event.ID == lastEventID })
+		missed = slices.Clone(s.replay[i+1:])
+	}
+
+	events = make(chan streamEvent, streamSubscriberBuffer)
+	s.subscribers[events] = struct{}{}
+	return events, missed, true
+}
+
+// unsubscribe removes a subscriber, unless it was already disconnected.
+func (s *chirpStream) unsubscribe(events chan streamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subscribers[events]; ok {
+		delete(s.subscribers, events)
+		close(events)
+	}
+}
+
+// streamChirpCreated streams a new chirp. The chirp is rendered once, as an
+// anonymous viewer would see it, since its engagement is the same for
+// everyone. Only the original it rechirps or quotes may be hidden from some
+// subscribers, so it is also rendered with the original as a tombstone.
+func (cfg *apiConfig) streamChirpCreated(ctx context.Context, id uint64, chirp database.Chirp) {
+	responses, err := cfg.chirpResponses(ctx, pgtype.UUID{}, []database.Chirp{chirp})
+	if err != nil {
+		log.Printf("Error streaming chirp %v: %v\n", chirp.ID, err)
+		return
+	}
+	response := responses[0]
+	event := streamEvent{ID: id, Type: streamEventChirpCreated, UserID: chirp.UserID}
+	event.Data, err = json.Marshal(response)
+	if err != nil {
+		log.Printf("Error marshalling chirp struct: %v\n", err)
+		return
+	}
+
+	if original := response.Original; original != nil && !original.Deleted {
+		event.OriginalUserID = original.UserID
+		tombstone := unavailableChirpResponse(original.ID)
+		response.Original = &tombstone
+		event.UnavailableOriginalData, err = json.Marshal(response)
+		if err != nil {
+			log.Printf("Error marshalling chirp struct: %v\n", err)
+			return
+		}
+	}
+	cfg.stream.publish(event)
+}
+
+// streamUser streams the public profile of a new or changed user, so that
+// clients can refresh the authors of the chirps they show.
+func (cfg *apiConfig) streamUser(id uint64, kind string, user database.User) {
+	data, err := json.Marshal(newPublicProfile(user))
+	if err != nil {
+		log.Printf("Error marshalling profile struct: %v\n", err)
+		return
+	}
+	cfg.stream.publish(streamEvent{ID: id, Type: kind, UserID: user.ID, Data: data})
+}
+
+func (cfg *apiConfig) handleStream(w http.ResponseWriter, r *http.Request) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	authorUUID := pgtype.UUID{}
+	if authorID := r.URL.Query().Get("author_id"); authorID != "" {
+		err = authorUUID.Scan(authorID)
+		if err != nil {
+			http.Error(w, "invalid author_id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// A malformed Last-Event-ID is ignored: the client gets the live stream
+	// as if it had not sent one.
+	lastEventID, err := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	resume := err == nil
+
+	// Events are filtered against the users hidden from the viewer as of
+	// subscribing, refreshed periodically, rather than querying the database
+	// for every event and subscriber.
+	hidden, err := cfg.allHiddenUsers(r.Context(), viewer)
+	if err != nil {
+		log.Printf("Error getting hidden users from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	events, missed, ok := cfg.stream.subscribe(lastEventID, resume)
+	if !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(streamHeartbeatInterval.Seconds())))
+		http.Error(w, "too many stream subscribers", http.StatusServiceUnavailable)
+		return
+	}
+	defer cfg.stream.unsubscribe(events)
+
+	// The stream outlives any write timeout of the server.
+	rc := http.NewResponseController(w)
+	err = rc.SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Error clearing stream write deadline: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// send writes an event unless the viewer should not see it. It returns
+	// false once the client is gone.
+	send := func(event streamEvent) bool {
+		if authorUUID.Valid && event.UserID != authorUUID {
+			return true
+		}
+		if hidden[event.UserID] {
+			return true
+		}
+		data := event.Data
+		if event.OriginalUserID.Valid && hidden[event.OriginalUserID] {
+			data = event.UnavailableOriginalData
+		}
+		_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		return err == nil
+	}
+
+	for _, event := range missed {
+		if !send(event) {
+			return
+		}
+	}
+	if rc.Flush() != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	refresh := time.NewTicker(streamHiddenUsersRefreshInterval)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+			if err != nil {
+				return
+			}
+		case <-refresh.C:
+			// On failure the stream keeps filtering with the previous set.
+			latest, err := cfg.allHiddenUsers(r.Context(), viewer)
+			if err != nil {
+				log.Printf("Error refreshing hidden users of stream: %v\n", err)
+				continue
+			}
+			hidden = latest
+		case event, ok := <-events:
+			if !ok {
+				// The subscriber fell behind and was disconnected.
+				return
+			}
+			if !send(event) {
+				return
+			}
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
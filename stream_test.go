@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testStreamEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// waitForStreamSubscribers waits for the streams of earlier subtests to be
+// unsubscribed, which happens once their handlers notice the client is gone.
+func (ts *testServer) waitForStreamSubscribers(t *testing.T, n int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		ts.cfg.stream.mu.Lock()
+		defer ts.cfg.stream.mu.Unlock()
+		return len(ts.cfg.stream.subscribers) == n
+	}, time.Second, 10*time.Millisecond)
+}
+
+// openStream subscribes to path on server with the given request headers and
+// returns a reader of its events. The stream is closed when the test ends.
+func openStream(t *testing.T, server *httptest.Server, path string, header http.Header) (*http.Response, func() testStreamEvent) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+	require.NoError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	scanner := bufio.NewScanner(resp.Body)
+	next := func() testStreamEvent {
+		t.Helper()
+
+		var event testStreamEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if event.Event != "" {
+					return event
+				}
+			case strings.HasPrefix(line, "id: "):
+				event.ID = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "event: "):
+				event.Event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+		require.NoError(t, scanner.Err())
+		t.Fatal("stream ended")
+		return event
+	}
+	return resp, next
+}
+
+func TestChirpStream(t *testing.T) {
+	author := pgtype.UUID{Valid: true}
+
+	t.Run("Resume", func(t *testing.T) {
+		stream := newChirpStream(1, 3)
+		// IDs from the bus need not increase in delivery order.
+		for i, data := range []string{"a", "b", "c", "d"} {
+			stream.publish(streamEvent{ID: uint64(10 + []int{0, 2, 1, 3}[i]), Type: streamEventChirpCreated, UserID: author, Data: []byte(data)})
+		}
+
+		events, missed, ok := stream.subscribe(12, true)
+		require.True(t, ok)
+		require.Len(t, missed, 2)
+		assert.Equal(t, "c", string(missed[0].Data))
+		assert.Equal(t, "d", string(missed[1].Data))
+		stream.unsubscribe(events)
+
+		// Events after lastEventID were dropped from the buffer, so all of it
+		// is replayed.
+		events, missed, _ = stream.subscribe(10, true)
+		assert.Len(t, missed, 3)
+		stream.unsubscribe(events)
+
+		events, missed, _ = stream.subscribe(13, true)
+		assert.Empty(t, missed)
+		stream.unsubscribe(events)
+	})
+
+	t.Run("Subscriber Cap", func(t *testing.T) {
+		stream := newChirpStream(1, 3)
+		events, _, ok := stream.subscribe(0, false)
+		require.True(t, ok)
+		_, _, ok = stream.subscribe(0, false)
+		assert.False(t, ok)
+
+		stream.unsubscribe(events)
+		_, _, ok = stream.subscribe(0, false)
+		assert.True(t, ok)
+	})
+
+	t.Run("Slow Subscribers Are Disconnected", func(t *testing.T) {
+		stream := newChirpStream(1, 3)
+		events, _, _ := stream.subscribe(0, false)
+		for i := range streamSubscriberBuffer + 1 {
+			stream.publish(streamEvent{ID: uint64(i), Type: streamEventChirpCreated, UserID: author})
+		}
+
+		for range streamSubscriberBuffer {
+			<-events
+		}
+		_, ok := <-events
+		assert.False(t, ok)
+		assert.Empty(t, stream.subscribers)
+		stream.unsubscribe(events)
+	})
+}
+
+func TestStream(t *testing.T) {
+	ts := newTestServer(t)
+	server := httptest.NewServer(ts.mux)
+	t.Cleanup(server.Close)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	t.Run("Invalid Author", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/stream?author_id=nobody", "", nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	var lastEventID string
+	var deleted testChirp
+
+	t.Run("Created And Deleted Chirps", func(t *testing.T) {
+		resp, next := openStream(t, server, "/api/stream?author_id="+alice.ID, nil)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		ts.createChirp(bob, "not streamed")
+		deleted = ts.createChirp(alice, "streamed")
+		event := next()
+		assert.Equal(t, streamEventChirpCreated, event.Event)
+		var chirp testChirp
+		require.NoError(t, json.Unmarshal([]byte(event.Data), &chirp))
+		assert.Equal(t, deleted, chirp)
+
+		rec := ts.do(http.MethodDelete, "/api/chirps/"+deleted.ID, alice.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		event = next()
+		assert.Equal(t, streamEventChirpDeleted, event.Event)
+		assert.JSONEq(t, `{"id":"`+deleted.ID+`","user_id":"`+alice.ID+`"}`, event.Data)
+		lastEventID = event.ID
+	})
+
+	t.Run("Resume From Last Event ID", func(t *testing.T) {
+		created := ts.createChirp(alice, "while disconnected")
+		ts.waitForStreamSubscribers(t, 0)
+
+		_, next := openStream(t, server, "/api/stream", http.Header{"Last-Event-Id": {lastEventID}})
+		event := next()
+		assert.Equal(t, streamEventChirpCreated, event.Event)
+		assert.Contains(t, event.Data, created.ID)
+	})
+
+	t.Run("Blocked Authors", func(t *testing.T) {
+		ts.block(bob, alice)
+		ts.waitForStreamSubscribers(t, 0)
+
+		_, next := openStream(t, server, "/api/stream", http.Header{"Authorization": {bob.bearer()}})
+		ts.createChirp(alice, "hidden from bob")
+		visible := ts.createChirp(bob, "visible to bob")
+		assert.Contains(t, next().Data, visible.ID)
+	})
+
+	t.Run("Quotes Of Blocked Authors", func(t *testing.T) {
+		carol := ts.signup("carol@example.com")
+		original := ts.createChirp(alice, "quoted by carol")
+		ts.waitForStreamSubscribers(t, 0)
+
+		_, nextForBob := openStream(t, server, "/api/stream", http.Header{"Authorization": {bob.bearer()}})
+		_, nextForCarol := openStream(t, server, "/api/stream", http.Header{"Authorization": {carol.bearer()}})
+		ts.waitForStreamSubscribers(t, 2)
+		rec := ts.do(http.MethodPost, "/api/chirps", carol.bearer(), map[string]string{"body": "a quote", "quote_of": original.ID})
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		originalOf := func(event testStreamEvent) map[string]any {
+			var chirp map[string]any
+			require.NoError(t, json.Unmarshal([]byte(event.Data), &chirp))
+			require.Contains(t, chirp, "original")
+			return chirp["original"].(map[string]any)
+		}
+		quoted := originalOf(nextForBob())
+		assert.Equal(t, original.ID, quoted["id"])
+		assert.Equal(t, true, quoted["unavailable"])
+		assert.Empty(t, quoted["body"])
+
+		quoted = originalOf(nextForCarol())
+		assert.Equal(t, original.Body, quoted["body"])
+	})
+
+	t.Run("Too Many Subscribers", func(t *testing.T) {
+		ts.waitForStreamSubscribers(t, 0)
+		for range testMaxStreamSubscribers {
+			openStream(t, server, "/api/stream", nil)
+		}
+		resp, _ := openStream(t, server, "/api/stream", nil)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+	})
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/chtozamm/chirpy/internal/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// setReplyParent makes the chirp created with params a reply to parent.
+func setReplyParent(params *database.CreateChirpParams, parent database.Chirp) {
+	params.InReplyTo = parent.ID
+	params.ConversationID = parent.ConversationID
+	if !params.ConversationID.Valid {
+		params.ConversationID = parent.ID
+	}
+	params.AncestorIds = append(slices.Clone(parent.AncestorIds), parent.ID)
+}
+
+func (cfg *apiConfig) handleGetThread(w http.ResponseWriter, r *http.Request) {
+	viewer, err := cfg.viewer(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	chirpID := pgtype.UUID{}
+	err = chirpID.Scan(r.PathValue("chirpID"))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(context.Background(), chirpID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting chirp from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	page, err := parsePageRequest(r, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if page.Desc {
+		http.Error(w, "threads are always sorted oldest first", http.StatusBadRequest)
+		return
+	}
+
+	var ancestors []database.Chirp
+	if len(chirp.AncestorIds) > 0 {
+		ancestors, err = cfg.db.GetChirpsByIDs(context.Background(), chirp.AncestorIds)
+		if err != nil {
+			log.Printf("Error getting thread ancestors from db: %v\n", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	authors := []pgtype.UUID{chirp.UserID}
+	for _, ancestor := range ancestors {
+		authors = append(authors, ancestor.UserID)
+	}
+	hidden, err := cfg.hiddenUsers(context.Background(), viewer, authors)
+	if err != nil {
+		log.Printf("Error getting hidden users from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if hidden[chirp.UserID] {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	unavailable := make(map[pgtype.UUID]bool)
+	var visibleAncestors []database.Chirp
+	for _, ancestor := range ancestors {
+		if hidden[ancestor.UserID] {
+			unavailable[ancestor.ID] = true
+			continue
+		}
+		visibleAncestors = append(visibleAncestors, ancestor)
+	}
+	ancestors = visibleAncestors
+
+	descendants, err := cfg.db.GetThreadDescendants(context.Background(), database.GetThreadDescendantsParams{
+		ChirpID:         chirp.ID,
+		CursorCreatedAt: page.Cursor.Timestamp(),
+		CursorID:        page.Cursor.UUID(),
+		ViewerID:        viewer,
+		PageSize:        page.QueryLimit(),
+	})
+	if err != nil {
+		log.Printf("Error getting thread descendants from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	descendants, next := trimPage(descendants, page, chirpCursor)
+
+	tombstones, err := cfg.descendantTombstones(context.Background(), viewer, chirp.ID, descendants)
+	if err != nil {
+		log.Printf("Error getting thread descendants from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// Build every response at once so engagement is looked up only once.
+	chirps := append(append(ancestors, chirp), descendants...)
+	responses, err := cfg.chirpResponses(context.Background(), viewer, chirps)
+	if err != nil {
+		log.Printf("Error getting chirp engagement from db: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	byID := make(map[pgtype.UUID]chirpResponse, len(ancestors))
+	for _, response := range responses[:len(ancestors)] {
+		byID[response.ID] = response
+	}
+
+	type response struct {
+		Ancestors   []chirpResponse `json:"ancestors"`
+		Chirp       chirpResponse   `json:"chirp"`
+		Descendants []chirpResponse `json:"descendants"`
+	}
+
+	// Ancestors are listed root first; deleted and hidden ones keep their
+	// place as tombstones.
+	thread := response{
+		Ancestors:   []chirpResponse{},
+		Chirp:       responses[len(ancestors)],
+		Descendants: []chirpResponse{},
+	}
+	for i, descendant := range responses[len(ancestors)+1:] {
+		thread.Descendants = append(thread.Descendants, tombstones[descendants[i].ID]...)
+		thread.Descendants = append(thread.Descendants, descendant)
+	}
+	for _, id := range chirp.AncestorIds {
+		ancestor, ok := byID[id]
+		if unavailable[id] {
+			ancestor = unavailableChirpResponse(id)
+		} else if !ok {
+			ancestor = deletedChirpResponse(id)
+		}
+		thread.Ancestors = append(thread.Ancestors, ancestor)
+	}
+
+	resp, err := json.Marshal(thread)
+	if err != nil {
+		log.Printf("Error marshalling thread: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	setNextPageHeaders(w, r, next)
+	w.Write(resp)
+}
+
+// descendantTombstones returns the tombstones to list before each of
+// descendants, a page of the replies below chirpID. Every reply in between
+// that was deleted or is hidden from viewer gets one, with its in_reply_to
+// set, so that the replies to it can still be placed in the thread. A
+// tombstone is listed before the first reply below it on each page.
+func (cfg *apiConfig) descendantTombstones(ctx context.Context, viewer, chirpID pgtype.UUID, descendants []database.Chirp) (map[pgtype.UUID][]chirpResponse, error) {
+	tombstones := make(map[pgtype.UUID][]chirpResponse)
+
+	// path returns the replies from chirpID down to the parent of descendant.
+	path := func(descendant database.Chirp) []pgtype.UUID {
+		return descendant.AncestorIds[slices.Index(descendant.AncestorIds, chirpID):]
+	}
+
+	onPage := make(map[pgtype.UUID]bool, len(descendants))
+	for _, descendant := range descendants {
+		onPage[descendant.ID] = true
+	}
+	var offPage []pgtype.UUID
+	seen := make(map[pgtype.UUID]bool)
+	for _, descendant := range descendants {
+		for _, id := range path(descendant)[1:] {
+			if !onPage[id] && !seen[id] {
+				seen[id] = true
+				offPage = append(offPage, id)
+			}
+		}
+	}
+	if len(offPage) == 0 {
+		return tombstones, nil
+	}
+
+	// Replies that still exist and are visible are on earlier pages.
+	parents, err := cfg.db.GetChirpsByIDs(ctx, offPage)
+	if err != nil {
+		return nil, err
+	}
+	var authors []pgtype.UUID
+	for _, parent := range parents {
+		authors = append(authors, parent.UserID)
+	}
+	hidden, err := cfg.hiddenUsers(ctx, viewer, authors)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[pgtype.UUID]bool, len(parents))
+	available := make(map[pgtype.UUID]bool, len(parents))
+	for _, parent := range parents {
+		exists[parent.ID] = true
+		available[parent.ID] = !hidden[parent.UserID]
+	}
+
+	listed := make(map[pgtype.UUID]bool)
+	for _, descendant := range descendants {
+		ids := path(descendant)
+		for i := 1; i < len(ids); i++ {
+			id := ids[i]
+			if onPage[id] || available[id] || listed[id] {
+				continue
+			}
+			listed[id] = true
+
+			tombstone := deletedChirpResponse(id)
+			if exists[id] {
+				tombstone = unavailableChirpResponse(id)
+			}
+			tombstone.InReplyTo = ids[i-1]
+			tombstones[descendant.ID] = append(tombstones[descendant.ID], tombstone)
+		}
+	}
+	return tombstones, nil
+}
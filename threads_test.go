@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testThread struct {
+	Ancestors   []testThreadChirp `json:"ancestors"`
+	Chirp       testThreadChirp   `json:"chirp"`
+	Descendants []testThreadChirp `json:"descendants"`
+}
+
+type testThreadChirp struct {
+	ID             string `json:"id"`
+	InReplyTo      string `json:"in_reply_to"`
+	ConversationID string `json:"conversation_id"`
+	ReplyCount     int64  `json:"reply_count"`
+	Deleted        bool   `json:"deleted"`
+	Unavailable    bool   `json:"unavailable"`
+}
+
+func (ts *testServer) reply(user testUser, parentID, body string) testThreadChirp {
+	ts.t.Helper()
+
+	rec := ts.do(http.MethodPost, "/api/chirps", user.bearer(), map[string]string{"body": body, "in_reply_to": parentID})
+	require.Equal(ts.t, http.StatusCreated, rec.Code, rec.Body.String())
+	return decode[testThreadChirp](ts.t, rec)
+}
+
+func TestThread(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+
+	root := ts.createChirp(alice, "root")
+	reply := ts.reply(bob, root.ID, "reply")
+	nested := ts.reply(alice, reply.ID, "nested")
+	sibling := ts.reply(bob, root.ID, "sibling")
+
+	t.Run("Reply Fields", func(t *testing.T) {
+		assert.Equal(t, root.ID, reply.InReplyTo)
+		assert.Equal(t, root.ID, reply.ConversationID)
+		assert.Equal(t, reply.ID, nested.InReplyTo)
+		assert.Equal(t, root.ID, nested.ConversationID)
+
+		rec := ts.do(http.MethodPost, "/api/chirps", bob.bearer(), map[string]string{"body": "x", "in_reply_to": bob.ID})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("From Root", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps/"+root.ID+"/thread", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		thread := decode[testThread](t, rec)
+		assert.Empty(t, thread.Ancestors)
+		assert.Equal(t, root.ID, thread.Chirp.ID)
+		assert.EqualValues(t, 2, thread.Chirp.ReplyCount)
+		require.Len(t, thread.Descendants, 3)
+		assert.Equal(t, []string{reply.ID, nested.ID, sibling.ID}, []string{
+			thread.Descendants[0].ID, thread.Descendants[1].ID, thread.Descendants[2].ID,
+		})
+	})
+
+	t.Run("Paginated", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/chirps/"+root.ID+"/thread?limit=2", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, decode[testThread](t, rec).Descendants, 2)
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+root.ID+"/thread?limit=2&cursor="+rec.Header().Get("X-Next-Cursor"), "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		thread := decode[testThread](t, rec)
+		require.Len(t, thread.Descendants, 1)
+		assert.Equal(t, sibling.ID, thread.Descendants[0].ID)
+	})
+
+	t.Run("Deleted Parent", func(t *testing.T) {
+		rec := ts.do(http.MethodDelete, "/api/chirps/"+reply.ID, bob.bearer(), nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodGet, "/api/chirps/"+nested.ID+"/thread", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		thread := decode[testThread](t, rec)
+		require.Len(t, thread.Ancestors, 2)
+		assert.Equal(t, testThreadChirp{ID: root.ID, ReplyCount: 1}, thread.Ancestors[0])
+		assert.Equal(t, testThreadChirp{ID: reply.ID, Deleted: true}, thread.Ancestors[1])
+
+		// The deleted reply keeps its place so the nested reply is not an
+		// orphan.
+		rec = ts.do(http.MethodGet, "/api/chirps/"+root.ID+"/thread", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		thread = decode[testThread](t, rec)
+		require.Len(t, thread.Descendants, 3)
+		assert.Equal(t, testThreadChirp{ID: reply.ID, InReplyTo: root.ID, Deleted: true}, thread.Descendants[0])
+		assert.Equal(t, nested.ID, thread.Descendants[1].ID)
+		assert.Equal(t, sibling.ID, thread.Descendants[2].ID)
+
+		// It is listed on every page with replies below it, while replies that
+		// still exist are only listed on their own page.
+		deeper := ts.reply(bob, nested.ID, "deeper")
+		rec = ts.do(http.MethodGet, "/api/chirps/"+root.ID+"/thread?limit=2", "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = ts.do(http.MethodGet, "/api/chirps/"+root.ID+"/thread?limit=2&cursor="+rec.Header().Get("X-Next-Cursor"), "", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		thread = decode[testThread](t, rec)
+		require.Len(t, thread.Descendants, 2)
+		assert.Equal(t, testThreadChirp{ID: reply.ID, InReplyTo: root.ID, Deleted: true}, thread.Descendants[0])
+		assert.Equal(t, deeper.ID, thread.Descendants[1].ID)
+	})
+	t.Run("Hidden Parent", func(t *testing.T) {
+		carol := ts.signup("carol@example.com")
+		answer := ts.reply(carol, sibling.ID, "answer")
+		ts.block(alice, bob)
+
+		rec := ts.do(http.MethodGet, "/api/chirps/"+root.ID+"/thread", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		thread := decode[testThread](t, rec)
+		require.Len(t, thread.Descendants, 4)
+		assert.Equal(t, testThreadChirp{ID: reply.ID, InReplyTo: root.ID, Deleted: true}, thread.Descendants[0])
+		assert.Equal(t, nested.ID, thread.Descendants[1].ID)
+		assert.Equal(t, testThreadChirp{ID: sibling.ID, InReplyTo: root.ID, Unavailable: true}, thread.Descendants[2])
+		assert.Equal(t, answer.ID, thread.Descendants[3].ID)
+	})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTimeline(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.signup("alice@example.com")
+	bob := ts.signup("bob@example.com")
+	carol := ts.signup("carol@example.com")
+	ts.follow(alice, bob)
+
+	own := ts.createChirp(alice, "mine")
+	followed := ts.createChirp(bob, "followed")
+	ts.createChirp(carol, "not followed")
+	latest := ts.createChirp(bob, "followed again")
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/timeline", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Own And Followed", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/timeline", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []testChirp{latest, followed, own}, decode[[]testChirp](t, rec))
+	})
+
+	t.Run("Paginated", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/timeline?limit=2", alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []testChirp{latest, followed}, decode[[]testChirp](t, rec))
+
+		rec = ts.do(http.MethodGet, "/api/timeline?limit=2&cursor="+rec.Header().Get("X-Next-Cursor"), alice.bearer(), nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, []testChirp{own}, decode[[]testChirp](t, rec))
+	})
+
+	t.Run("Ascending Rejected", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/timeline?sort=asc", alice.bearer(), nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
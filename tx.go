@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+
+	"github.com/chtozamm/chirpy/internal/database"
+)
+
+// inTx runs fn with queries that are committed together if it returns nil
+// and rolled back otherwise. Without a connection pool, as in tests against
+// the in-memory store, fn runs on cfg.db and nothing is rolled back.
+func (cfg *apiConfig) inTx(ctx context.Context, fn func(db database.Querier) error) error {
+	if cfg.dbPool == nil {
+		return fn(cfg.db)
+	}
+
+	tx, err := cfg.dbPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Rolling back after a commit is a no-op.
+	defer tx.Rollback(context.Background())
+
+	err = fn(database.New(tx))
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
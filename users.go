@@ -20,6 +20,7 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		Handle   string `json:"handle"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -40,6 +41,16 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	handle := pgtype.Text{}
+	if params.Handle != "" {
+		err = validateHandle(params.Handle)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handle = pgtype.Text{String: params.Handle, Valid: true}
+	}
+
 	hashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		log.Printf("Error hashing password: %v\n", err)
@@ -47,17 +58,21 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newUser, err := cfg.db.CreateUser(context.Background(), database.CreateUserParams{Email: params.Email, HashedPassword: hashedPassword})
+	newUser, err := cfg.db.CreateUser(context.Background(), database.CreateUserParams{
+		Email:          params.Email,
+		HashedPassword: hashedPassword,
+		Handle:         handle,
+	})
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-			http.Error(w, "email already exists", http.StatusConflict)
+		if msg, ok := userConflictMessage(err); ok {
+			http.Error(w, msg, http.StatusConflict)
 			return
 		}
 		log.Printf("Error creating a user: %v\n", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	cfg.publishEvent(context.Background(), eventUserCreated, userEvent{ID: newUser.ID})
 
 	type response struct {
 		ID          pgtype.UUID      `json:"id"`
@@ -65,6 +80,7 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 		UpdatedAt   pgtype.Timestamp `json:"updated_at"`
 		Email       string           `json:"email"`
 		IsChirpyRed bool             `json:"is_chirpy_red"`
+		Handle      pgtype.Text      `json:"handle"`
 	}
 
 	resp, err := json.Marshal(response{
@@ -73,6 +89,7 @@ func (cfg *apiConfig) handleCreateUser(w http.ResponseWriter, r *http.Request) {
 		UpdatedAt:   newUser.UpdatedAt,
 		Email:       newUser.Email,
 		IsChirpyRed: newUser.IsChirpyRed,
+		Handle:      newUser.Handle,
 	})
 	if err != nil {
 		log.Printf("Error marshalling response struct: %v\n", err)
@@ -268,41 +285,68 @@ func (cfg *apiConfig) handleRevokeRefreshToken(w http.ResponseWriter, r *http.Re
 }
 
 func (cfg *apiConfig) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	// Validate access token before anything else, so that unauthenticated
+	// callers cannot probe the validation rules.
+	userID, err := cfg.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
 	// Parse request body
+	// Profile fields are pointers so that they can be cleared with "".
 	type parameters struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email       string  `json:"email"`
+		Password    string  `json:"password"`
+		Handle      string  `json:"handle"`
+		DisplayName *string `json:"display_name"`
+		Bio         *string `json:"bio"`
+		AvatarURL   *string `json:"avatar_url"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
-	err := decoder.Decode(&params)
+	err = decoder.Decode(&params)
 	if err != nil {
 		log.Printf("Error decoding parameters: %v\n", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	if params.Email == "" && params.Password == "" {
+	if params.Email == "" && params.Password == "" && params.Handle == "" &&
+		params.DisplayName == nil && params.Bio == nil && params.AvatarURL == nil {
 		http.Error(w, "No data to update provided", http.StatusBadRequest)
 		return
 	}
 
-	// Validate access token
-	accessToken, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		http.Error(w, "Access token is missing or invalid in the Authorization header", http.StatusUnauthorized)
-		return
+	if params.Handle != "" {
+		err = validateHandle(params.Handle)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
-
-	id, err := auth.ValidateJWT(accessToken, cfg.authSecret)
-	if err != nil {
-		http.Error(w, "Invalid access token", http.StatusUnauthorized)
-		return
+	if params.DisplayName != nil {
+		err = validateDisplayName(*params.DisplayName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if params.Bio != nil {
+		err = validateBio(*params.Bio)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if params.AvatarURL != nil {
+		err = validateAvatarURL(*params.AvatarURL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
-
-	userID := pgtype.UUID{}
-	userID.Scan(id.String())
 
 	user, err := cfg.db.GetUserByID(context.Background(), userID)
 	if err != nil {
@@ -325,6 +369,19 @@ func (cfg *apiConfig) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		user.HashedPassword = hashedPassword
 	}
 
+	if params.Handle != "" {
+		user.Handle = pgtype.Text{String: params.Handle, Valid: true}
+	}
+	if params.DisplayName != nil {
+		user.DisplayName = *params.DisplayName
+	}
+	if params.Bio != nil {
+		user.Bio = *params.Bio
+	}
+	if params.AvatarURL != nil {
+		user.AvatarUrl = *params.AvatarURL
+	}
+
 	timestamp := pgtype.Timestamp{}
 	timestamp.Scan(time.Now().UTC())
 
@@ -332,26 +389,43 @@ func (cfg *apiConfig) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
 		ID:             userID,
 		Email:          user.Email,
 		HashedPassword: user.HashedPassword,
+		Handle:         user.Handle,
+		DisplayName:    user.DisplayName,
+		Bio:            user.Bio,
+		AvatarUrl:      user.AvatarUrl,
 		UpdatedAt:      timestamp,
 	})
 	if err != nil {
+		if msg, ok := userConflictMessage(err); ok {
+			http.Error(w, msg, http.StatusConflict)
+			return
+		}
 		log.Printf("Error updating user in database: %v\n", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	cfg.publishEvent(context.Background(), eventUserUpdated, userEvent{ID: updatedUser.ID})
 
 	type response struct {
-		ID        pgtype.UUID      `json:"id"`
-		CreatedAt pgtype.Timestamp `json:"created_at"`
-		UpdatedAt pgtype.Timestamp `json:"updated_at"`
-		Email     string           `json:"email"`
+		ID          pgtype.UUID      `json:"id"`
+		CreatedAt   pgtype.Timestamp `json:"created_at"`
+		UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+		Email       string           `json:"email"`
+		Handle      pgtype.Text      `json:"handle"`
+		DisplayName string           `json:"display_name"`
+		Bio         string           `json:"bio"`
+		AvatarURL   string           `json:"avatar_url"`
 	}
 
 	updatedUserResponse, err := json.Marshal(response{
-		ID:        updatedUser.ID,
-		CreatedAt: updatedUser.CreatedAt,
-		UpdatedAt: updatedUser.UpdatedAt,
-		Email:     updatedUser.Email,
+		ID:          updatedUser.ID,
+		CreatedAt:   updatedUser.CreatedAt,
+		UpdatedAt:   updatedUser.UpdatedAt,
+		Email:       updatedUser.Email,
+		Handle:      updatedUser.Handle,
+		DisplayName: updatedUser.DisplayName,
+		Bio:         updatedUser.Bio,
+		AvatarURL:   updatedUser.AvatarUrl,
 	})
 	if err != nil {
 		log.Printf("Error marshalling response struct: %v\n", err)
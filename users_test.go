@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateUser(t *testing.T) {
+	ts := newTestServer(t)
+
+	t.Run("Created", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users", "", map[string]string{"email": "a@example.com", "password": "password"})
+		require.Equal(t, http.StatusCreated, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "hashed_password")
+	})
+
+	t.Run("Duplicate Email", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users", "", map[string]string{"email": "a@example.com", "password": "password"})
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Missing Password", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/users", "", map[string]string{"email": "b@example.com"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestAuthenticateUser(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+
+	t.Run("Wrong Password", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/login", "", map[string]string{"email": "a@example.com", "password": "wrong"})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Refresh And Revoke", func(t *testing.T) {
+		rec := ts.do(http.MethodPost, "/api/refresh", "Bearer "+user.RefreshToken, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotEmpty(t, decode[map[string]string](t, rec)["token"])
+
+		rec = ts.do(http.MethodPost, "/api/revoke", "Bearer "+user.RefreshToken, nil)
+		require.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = ts.do(http.MethodPost, "/api/refresh", "Bearer "+user.RefreshToken, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+
+	rec := ts.do(http.MethodPut, "/api/users", user.bearer(), map[string]string{"email": "b@example.com"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "b@example.com", decode[testUser](t, rec).Email)
+
+	rec = ts.do(http.MethodPut, "/api/users", "", map[string]string{"email": "c@example.com"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestUpgradeUser(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.signup("a@example.com")
+	event := map[string]any{"event": "user.upgraded", "data": map[string]string{"user_id": user.ID}}
+
+	rec := ts.do(http.MethodPost, "/api/polka/webhooks", "ApiKey wrong", event)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = ts.do(http.MethodPost, "/api/polka/webhooks", "ApiKey "+ts.cfg.polkaKey, event)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = ts.do(http.MethodPost, "/api/login", "", map[string]string{"email": "a@example.com", "password": "password"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, decode[testUser](t, rec).IsChirpyRed)
+}
@@ -62,6 +62,7 @@ func (cfg *apiConfig) handleUpgradeUser(w http.ResponseWriter, r *http.Request)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	cfg.publishEvent(context.Background(), eventUserUpgraded, userEvent{ID: userID})
 
 	w.WriteHeader(http.StatusNoContent)
 }
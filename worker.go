@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runPeriodically runs task every interval until ctx is done. Errors are
+// logged and the task is retried at the next tick.
+func runPeriodically(ctx context.Context, name string, interval time.Duration, task func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := task(ctx)
+		if err != nil {
+			log.Printf("Error running %s: %v\n", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}